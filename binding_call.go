@@ -1,9 +1,72 @@
 package playwright
 
+import (
+	"fmt"
+	"log"
+)
+
 type BindingCall struct {
 	ChannelOwner
 }
 
+// BindingSource is the origin of a call of a function of ExposeBinding.
+type BindingSource struct {
+	Context *BrowserContext
+	Page    *Page
+	Frame   *Frame
+}
+
+// BindingCallFunction is a function of ExposeBinding, its result is passed
+// back to the calling JavaScript.
+type BindingCallFunction func(source *BindingSource, args ...interface{}) interface{}
+
+// ExposedFunction is a function of ExposeFunction.
+type ExposedFunction func(args ...interface{}) interface{}
+
+// call calls the binding with the arguments of the JavaScript call and
+// resolves its promise with the result. A panic of the binding rejects it.
+func (b *BindingCall) call(binding BindingCallFunction) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("%v", r)
+			}
+			if _, sendErr := b.channel.Send("reject", map[string]interface{}{
+				"error": map[string]interface{}{
+					"error": map[string]interface{}{
+						"name":    "Error",
+						"message": err.Error(),
+						"stack":   "",
+					},
+				},
+			}); sendErr != nil {
+				log.Printf("could not reject binding call: %v", sendErr)
+			}
+		}
+	}()
+	frame := fromChannel(b.initializer["frame"]).(*Frame)
+	source := &BindingSource{
+		Frame: frame,
+		Page:  frame.Page(),
+	}
+	if source.Page != nil {
+		source.Context = source.Page.Context()
+	}
+	args := make([]interface{}, 0)
+	if values, ok := b.initializer["args"].([]interface{}); ok {
+		for _, value := range values {
+			args = append(args, parseValue(value))
+		}
+	}
+	result := binding(source, args...)
+	if _, err := b.channel.Send("resolve", map[string]interface{}{
+		"result": serializeArgument(result),
+	}); err != nil {
+		log.Printf("could not resolve binding call: %v", err)
+	}
+}
+
 func newBindingCall(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *BindingCall {
 	bt := &BindingCall{}
 	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
@@ -0,0 +1,90 @@
+package playwright
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingCallResolveAndReject(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Frame",
+			"guid":        "Frame",
+			"initializer": map[string]interface{}{"name": "", "url": "about:blank"},
+		},
+	})
+	for i, guid := range []string{"BindingCall@1", "BindingCall@2"} {
+		connection.Dispatch(&Message{
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type": "BindingCall",
+				"guid": guid,
+				"initializer": map[string]interface{}{
+					"frame": map[string]interface{}{"guid": "Frame"},
+					"name":  "add",
+					"args": []interface{}{
+						map[string]interface{}{"n": float64(i + 1)},
+						map[string]interface{}{"n": float64(2)},
+					},
+				},
+			},
+		})
+	}
+	frames := make(chan *Frame, 2)
+	add := func(source *BindingSource, args ...interface{}) interface{} {
+		frames <- source.Frame
+		if args[0].(int) == 2 {
+			panic(errors.New("no twos"))
+		}
+		return args[0].(int) + args[1].(int)
+	}
+
+	go fromChannel(connection.objects["BindingCall@1"].channel).(*BindingCall).call(add)
+	message := <-fake.sent
+	require.Equal(t, "resolve", message["method"])
+	require.Equal(t, map[string]interface{}{
+		"result": map[string]interface{}{
+			"value":   map[string]interface{}{"n": 3},
+			"handles": []interface{}{},
+		},
+	}, message["params"])
+	connection.Dispatch(&Message{ID: message["id"].(int)})
+	require.Equal(t, fromChannel(connection.objects["Frame"].channel), <-frames)
+
+	go fromChannel(connection.objects["BindingCall@2"].channel).(*BindingCall).call(add)
+	message = <-fake.sent
+	require.Equal(t, "reject", message["method"])
+	require.Equal(t, "no twos", message["params"].(map[string]interface{})["error"].(map[string]interface{})["error"].(map[string]interface{})["message"])
+	connection.Dispatch(&Message{ID: message["id"].(int)})
+	require.Equal(t, fromChannel(connection.objects["Frame"].channel), <-frames)
+}
+
+func TestExposeBindingFailed(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "BrowserContext",
+			"guid":        "BrowserContext",
+			"initializer": map[string]interface{}{},
+		},
+	})
+	context := fromChannel(connection.objects["BrowserContext"].channel).(*BrowserContext)
+	go func() {
+		message := <-fake.sent
+		connection.Dispatch(&Message{
+			ID: message["id"].(int),
+			Error: &struct {
+				Error errorPayload `json:"error"`
+			}{Error: errorPayload{Name: "Error", Message: "Target closed"}},
+		})
+	}()
+	require.Error(t, context.ExposeBinding("add", func(source *BindingSource, args ...interface{}) interface{} {
+		return nil
+	}))
+	require.Nil(t, context.getBinding("add"))
+}
@@ -1,8 +1,11 @@
 package playwright
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 type Browser struct {
@@ -10,54 +13,222 @@ type Browser struct {
 	IsConnected bool
 	contexts    []*BrowserContext
 	contextsMu  sync.Mutex
+	isRemote    bool
+	browserType *BrowserType
+	closeOnce   sync.Once
+	closeReason atomic.Value
+	// connectionClosed disconnects the browser if the connection terminates,
+	// it is removed once the browser is closed
+	connectionClosed *closeHandler
+	// videosPath is the VideosPath launch option, into which the driver
+	// writes the recordings of RecordVideo
+	videosPath string
+}
+
+// BrowserCloseOptions are the options of Browser.Close.
+type BrowserCloseOptions struct {
+	// Reason is added to the errors of the operations which fail because the
+	// browser gets closed, e.g. "shutting down the worker".
+	Reason *string
+}
+
+// browserCloseReason returns the reason of Browser.Close of the browser to
+// which the object belongs.
+func browserCloseReason(owner *ChannelOwner) string {
+	for ; owner != nil; owner = owner.parent {
+		if browser, ok := owner.channel.object.(*Browser); ok {
+			reason, _ := browser.closeReason.Load().(string)
+			return reason
+		}
+	}
+	return ""
 }
 
 func (b *Browser) NewContext(options ...BrowserNewContextOptions) (*BrowserContext, error) {
-	channel, err := b.channel.Send("newContext", options)
+	return b.NewContextContext(context.Background(), options...)
+}
+
+// NewContextContext is like NewContext but stops waiting once ctx is done.
+func (b *Browser) NewContextContext(ctx context.Context, options ...BrowserNewContextOptions) (*BrowserContext, error) {
+	overrides := map[string]interface{}{}
+	if len(options) == 1 && options[0].ExtraHTTPHeaders != nil {
+		overrides["extraHTTPHeaders"] = serializeHeaders(options[0].ExtraHTTPHeaders)
+	}
+	if len(options) == 1 && options[0].NoViewport != nil && *options[0].NoViewport {
+		overrides["noDefaultViewport"] = true
+		option := options[0]
+		option.Viewport = nil
+		options = []BrowserNewContextOptions{option}
+	}
+	if len(options) == 1 && options[0].RecordVideo != nil {
+		recordVideo := options[0].RecordVideo
+		if recordVideo.Size != nil {
+			overrides["_recordVideos"] = recordVideo.Size
+		} else {
+			overrides["_recordVideos"] = map[string]interface{}{}
+		}
+	}
+	var storageState *StorageState
+	if len(options) == 1 {
+		storageState = options[0].StorageState
+		if options[0].StorageStatePath != nil {
+			var err error
+			storageState, err = readStorageState(*options[0].StorageStatePath)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	channel, err := b.channel.SendContext(ctx, "newContext", options, overrides)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message: %w", err)
 	}
-	context := fromChannel(channel).(*BrowserContext)
-	context.browser = b
+	browserContext := fromChannel(channel).(*BrowserContext)
+	browserContext.browser = b
+	if len(options) == 1 && options[0].RecordVideo != nil {
+		browserContext.recordVideo = true
+		if options[0].RecordVideo.Dir != nil {
+			browserContext.recordVideoDir = *options[0].RecordVideo.Dir
+		}
+	}
+	browserContext.applyDefaultTimeouts()
 	b.contextsMu.Lock()
-	b.contexts = append(b.contexts, context)
+	b.contexts = append(b.contexts, browserContext)
 	b.contextsMu.Unlock()
-	return context, nil
+	if storageState != nil {
+		if err := browserContext.setStorageState(storageState); err != nil {
+			browserContext.Close()
+			return nil, fmt.Errorf("could not set storage state: %w", err)
+		}
+	}
+	return browserContext, nil
 }
 
 func (b *Browser) NewPage(options ...BrowserNewContextOptions) (*Page, error) {
-	context, err := b.NewContext(options...)
+	return b.NewPageContext(context.Background(), options...)
+}
+
+// NewPageContext is like NewPage but stops waiting once ctx is done.
+func (b *Browser) NewPageContext(ctx context.Context, options ...BrowserNewContextOptions) (*Page, error) {
+	browserContext, err := b.NewContextContext(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
-	page, err := context.NewPage()
+	page, err := browserContext.NewPageContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	page.ownedContext = context
-	context.ownedPage = page
+	page.ownedContext = browserContext
+	browserContext.ownedPage = page
 	return page, nil
 }
 
+// Contexts returns the open contexts of the browser in the order of their
+// creation, e.g. to close the ones which are older than their CreatedAt limit.
 func (b *Browser) Contexts() []*BrowserContext {
 	b.contextsMu.Lock()
 	defer b.contextsMu.Unlock()
-	return b.contexts
+	contexts := make([]*BrowserContext, len(b.contexts))
+	copy(contexts, b.contexts)
+	return contexts
 }
 
-func (b *Browser) Close() error {
-	_, err := b.channel.Send("close")
+// Close closes the browser and all of its pages. The Reason option explains
+// to the operations which are still in flight why they failed.
+func (b *Browser) Close(options ...BrowserCloseOptions) error {
+	return b.CloseContext(context.Background(), options...)
+}
+
+// CloseContext is like Close but stops waiting once ctx is done.
+func (b *Browser) CloseContext(ctx context.Context, options ...BrowserCloseOptions) error {
+	if len(options) == 1 && options[0].Reason != nil {
+		b.closeReason.Store(*options[0].Reason)
+	}
+	_, err := b.channel.SendContext(ctx, "close")
+	if b.isRemote {
+		// The connection belongs only to this browser, see BrowserType.Connect.
+		if stopErr := b.connection.Stop(); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}
 	return err
 }
 
+// NewBrowserCDPSession creates a CDP session for the whole browser, e.g. for
+// the Target or SystemInfo domains. Only Chromium supports it.
+func (b *Browser) NewBrowserCDPSession() (*CDPSession, error) {
+	channel, err := b.channel.Send("crNewBrowserCDPSession")
+	if err != nil {
+		return nil, fmt.Errorf("could not send message: %w", err)
+	}
+	return fromChannel(channel).(*CDPSession), nil
+}
+
+// StartTracing starts a Chromium performance trace, which can be opened in
+// the Performance panel of the DevTools or chrome://tracing. Only one trace
+// can be active per browser. The Page option limits it to one page.
+func (b *Browser) StartTracing(options ...ChromiumBrowserStartTracingOptions) error {
+	overrides := map[string]interface{}{}
+	if len(options) == 1 {
+		option := options[0]
+		if page, ok := option.Page.(*Page); ok {
+			overrides["page"] = page.channel
+		}
+		option.Page = nil
+		options = []ChromiumBrowserStartTracingOptions{option}
+	}
+	_, err := b.channel.Send("crStartTracing", options, overrides)
+	return err
+}
+
+// StopTracing stops the trace of StartTracing and returns it. It is written
+// to the Path of StartTracing as well if one was given.
+func (b *Browser) StopTracing() ([]byte, error) {
+	binary, err := b.channel.Send("crStopTracing")
+	if err != nil {
+		return nil, err
+	}
+	trace, err := base64.StdEncoding.DecodeString(binary.(string))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode base64 :%w", err)
+	}
+	return trace, nil
+}
+
+// Version returns the version of the browser, e.g. "88.0.4316.0".
 func (b *Browser) Version() string {
 	return b.initializer["version"].(string)
 }
 
+// BrowserType returns the BrowserType which launched or connected the
+// browser, its Name is the engine of the browser.
+func (b *Browser) BrowserType() *BrowserType {
+	return b.browserType
+}
+
 func newBrowser(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *Browser {
 	bt := &Browser{
 		IsConnected: true,
 	}
 	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
+	bt.channel.On("close", bt.didClose)
+	// the browser disconnects without a close event if the driver crashed or
+	// the connection to a remote browser dropped
+	bt.connectionClosed = &closeHandler{handle: func(err error) {
+		bt.didClose()
+	}}
+	bt.connection.addCloseHandler(bt.connectionClosed)
 	return bt
 }
+
+// didClose emits the "disconnected" event with the browser, once.
+func (b *Browser) didClose() {
+	b.closeOnce.Do(func() {
+		b.IsConnected = false
+		b.connection.removeCloseHandler(b.connectionClosed)
+		if b.browserType != nil {
+			b.browserType.removeBrowser(b)
+		}
+		b.Emit("disconnected", b)
+	})
+}
@@ -0,0 +1,96 @@
+package playwright
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// getChannelExecutablePaths returns the locations at which the branded browser
+// of the channel is installed on the given operating system.
+func getChannelExecutablePaths(goos, channel string) ([]string, error) {
+	switch goos {
+	case "linux":
+		paths := map[string]string{
+			"chrome":      "/opt/google/chrome/chrome",
+			"chrome-beta": "/opt/google/chrome-beta/chrome",
+			"chrome-dev":  "/opt/google/chrome-unstable/chrome",
+			"msedge":      "/opt/microsoft/msedge/msedge",
+			"msedge-beta": "/opt/microsoft/msedge-beta/msedge",
+			"msedge-dev":  "/opt/microsoft/msedge-dev/msedge",
+		}
+		if path, ok := paths[channel]; ok {
+			return []string{path}, nil
+		}
+	case "darwin":
+		paths := map[string]string{
+			"chrome":        "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"chrome-beta":   "/Applications/Google Chrome Beta.app/Contents/MacOS/Google Chrome Beta",
+			"chrome-dev":    "/Applications/Google Chrome Dev.app/Contents/MacOS/Google Chrome Dev",
+			"chrome-canary": "/Applications/Google Chrome Canary.app/Contents/MacOS/Google Chrome Canary",
+			"msedge":        "/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+			"msedge-beta":   "/Applications/Microsoft Edge Beta.app/Contents/MacOS/Microsoft Edge Beta",
+			"msedge-dev":    "/Applications/Microsoft Edge Dev.app/Contents/MacOS/Microsoft Edge Dev",
+			"msedge-canary": "/Applications/Microsoft Edge Canary.app/Contents/MacOS/Microsoft Edge Canary",
+		}
+		if path, ok := paths[channel]; ok {
+			return []string{path}, nil
+		}
+	case "windows":
+		suffixes := map[string]string{
+			"chrome":        `Google\Chrome\Application\chrome.exe`,
+			"chrome-beta":   `Google\Chrome Beta\Application\chrome.exe`,
+			"chrome-dev":    `Google\Chrome Dev\Application\chrome.exe`,
+			"chrome-canary": `Google\Chrome SxS\Application\chrome.exe`,
+			"msedge":        `Microsoft\Edge\Application\msedge.exe`,
+			"msedge-beta":   `Microsoft\Edge Beta\Application\msedge.exe`,
+			"msedge-dev":    `Microsoft\Edge Dev\Application\msedge.exe`,
+			"msedge-canary": `Microsoft\Edge SxS\Application\msedge.exe`,
+		}
+		if suffix, ok := suffixes[channel]; ok {
+			paths := make([]string, 0)
+			for _, env := range []string{"LOCALAPPDATA", "PROGRAMFILES", "PROGRAMFILES(X86)"} {
+				if prefix := os.Getenv(env); prefix != "" {
+					paths = append(paths, prefix+`\`+suffix)
+				}
+			}
+			return paths, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported browser channel %q on %s", channel, goos)
+}
+
+// getChannelExecutablePath returns the executable of the installed branded
+// browser of the channel, e.g. "chrome" or "msedge".
+func getChannelExecutablePath(channel string) (string, error) {
+	paths, err := getChannelExecutablePaths(runtime.GOOS, channel)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.FromSlash(path)); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("browser channel %q is not installed, looked at: %v", channel, paths)
+}
+
+// channelExecutablePath resolves the Channel launch option into the path of
+// the executable, which the driver launches instead of the downloaded browser.
+func (b *BrowserType) channelExecutablePath(channel, executablePath *string) (*string, error) {
+	if channel == nil {
+		return executablePath, nil
+	}
+	if b.Name() != "chromium" {
+		return nil, fmt.Errorf("browser channels are only supported by chromium, not by %s", b.Name())
+	}
+	if executablePath != nil {
+		return nil, fmt.Errorf("the Channel and ExecutablePath options cannot be used together")
+	}
+	path, err := getChannelExecutablePath(*channel)
+	if err != nil {
+		return nil, err
+	}
+	return &path, nil
+}
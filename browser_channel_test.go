@@ -0,0 +1,46 @@
+package playwright
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetChannelExecutablePaths(t *testing.T) {
+	paths, err := getChannelExecutablePaths("linux", "chrome")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/opt/google/chrome/chrome"}, paths)
+
+	paths, err = getChannelExecutablePaths("darwin", "msedge")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge"}, paths)
+
+	_, err = getChannelExecutablePaths("linux", "chrome-canary")
+	require.EqualError(t, err, `unsupported browser channel "chrome-canary" on linux`)
+}
+
+func TestBrowserTypeChannelExecutablePath(t *testing.T) {
+	chromium := &BrowserType{}
+	chromium.initializer = map[string]interface{}{"name": "chromium"}
+	firefox := &BrowserType{}
+	firefox.initializer = map[string]interface{}{"name": "firefox"}
+
+	executablePath, err := chromium.channelExecutablePath(nil, String("foo"))
+	require.NoError(t, err)
+	require.Equal(t, "foo", *executablePath)
+	_, err = chromium.channelExecutablePath(String("chrome"), String("foo"))
+	require.Error(t, err)
+	_, err = firefox.channelExecutablePath(String("chrome"), nil)
+	require.EqualError(t, err, "browser channels are only supported by chromium, not by firefox")
+	_, err = chromium.channelExecutablePath(String("foo"), nil)
+	require.Error(t, err)
+}
+
+func TestTransformOptionsSkipsGoOnlyOptions(t *testing.T) {
+	require.Equal(t, map[string]interface{}{
+		"executablePath": String("/opt/google/chrome/chrome"),
+	}, transformOptions(BrowserTypeLaunchOptions{
+		Channel:        String("chrome"),
+		ExecutablePath: String("/opt/google/chrome/chrome"),
+	}))
+}
@@ -1,10 +1,15 @@
 package playwright
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 type BrowserContext struct {
@@ -14,6 +19,56 @@ type BrowserContext struct {
 	pages           []*Page
 	ownedPage       *Page
 	browser         *Browser
+	createdAt       time.Time
+	closed          chan struct{}
+	// recordVideo is set by the RecordVideo option of Browser.NewContext
+	recordVideo    bool
+	recordVideoDir string
+	videosMu       sync.Mutex
+	videos         []*Video
+	bindingsMu     sync.Mutex
+	bindings       map[string]BindingCallFunction
+	routesMu       sync.Mutex
+	routes         []*routeHandlerEntry
+	// backgroundPages and serviceWorkers of Chromium
+	backgroundPages []*Page
+	serviceWorkers  []*Worker
+}
+
+// CreatedAt returns the time at which the context was created.
+func (b *BrowserContext) CreatedAt() time.Time {
+	return b.createdAt
+}
+
+// addVideo finishes the video when the context is closed, or right away if
+// it is already closed.
+func (b *BrowserContext) addVideo(video *Video) {
+	b.videosMu.Lock()
+	select {
+	case <-b.closed:
+		b.videosMu.Unlock()
+		video.finish()
+		return
+	default:
+	}
+	b.videos = append(b.videos, video)
+	b.videosMu.Unlock()
+}
+
+// applyDefaultTimeouts passes the defaults of Playwright.SetDefaultTimeout
+// and Playwright.SetDefaultNavigationTimeout on to a new context.
+func (b *BrowserContext) applyDefaultTimeouts() {
+	timeout, navigationTimeout := b.connection.timeoutSettings.explicit()
+	if timeout != nil {
+		b.channel.SendNoReply("setDefaultTimeoutNoReply", map[string]interface{}{
+			"timeout": *timeout,
+		})
+	}
+	if navigationTimeout != nil {
+		b.channel.SendNoReply("setDefaultNavigationTimeoutNoReply", map[string]interface{}{
+			"timeout": *navigationTimeout,
+		})
+	}
 }
 
 func (b *BrowserContext) SetDefaultNavigationTimeout(timeout int) {
@@ -30,20 +85,58 @@ func (b *BrowserContext) SetDefaultTimeout(timeout int) {
 	})
 }
 
+// Pages returns the open pages of the context, popups included, in the order
+// in which they were opened.
 func (b *BrowserContext) Pages() []*Page {
 	b.pagesMutex.Lock()
 	defer b.pagesMutex.Unlock()
-	return b.pages
+	pages := make([]*Page, len(b.pages))
+	copy(pages, b.pages)
+	return pages
+}
+
+// BackgroundPages returns the open background pages of the extensions of the
+// context, a new one is emitted as "backgroundpage". Only Chromium has them.
+func (b *BrowserContext) BackgroundPages() []*Page {
+	b.pagesMutex.Lock()
+	defer b.pagesMutex.Unlock()
+	pages := make([]*Page, len(b.backgroundPages))
+	copy(pages, b.backgroundPages)
+	return pages
+}
+
+// ServiceWorkers returns the running service workers of the context, a new
+// one is emitted as "serviceworker". Only Chromium supports them.
+func (b *BrowserContext) ServiceWorkers() []*Worker {
+	b.pagesMutex.Lock()
+	defer b.pagesMutex.Unlock()
+	workers := make([]*Worker, len(b.serviceWorkers))
+	copy(workers, b.serviceWorkers)
+	return workers
+}
+
+// OnPage calls the handler with every new page of the context, e.g. a popup
+// of window.open or of a link with target="_blank". It is called before the
+// page loads, so its handlers see all of the events of the page.
+func (b *BrowserContext) OnPage(handler func(page *Page)) {
+	b.On("page", handler)
 }
 
 func (b *BrowserContext) NewPage(options ...BrowserNewPageOptions) (*Page, error) {
-	channel, err := b.channel.Send("newPage", options)
+	return b.NewPageContext(context.Background(), options...)
+}
+
+// NewPageContext is like NewPage but stops waiting once ctx is done.
+func (b *BrowserContext) NewPageContext(ctx context.Context, options ...BrowserNewPageOptions) (*Page, error) {
+	channel, err := b.channel.SendContext(ctx, "newPage", options)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message: %w", err)
 	}
 	return fromChannel(channel).(*Page), nil
 }
 
+// Cookies returns the cookies of the context, only the ones which are sent to
+// one of the urls if any are given.
 func (b *BrowserContext) Cookies(urls ...string) ([]*NetworkCookie, error) {
 	result, err := b.channel.Send("cookies", map[string]interface{}{
 		"urls": urls,
@@ -59,6 +152,8 @@ func (b *BrowserContext) Cookies(urls ...string) ([]*NetworkCookie, error) {
 	return cookies, nil
 }
 
+// AddCookies adds the cookies to the context, its pages send them with their
+// matching requests.
 func (b *BrowserContext) AddCookies(cookies ...SetNetworkCookieParam) error {
 	_, err := b.channel.Send("addCookies", map[string]interface{}{
 		"cookies": cookies,
@@ -66,11 +161,15 @@ func (b *BrowserContext) AddCookies(cookies ...SetNetworkCookieParam) error {
 	return err
 }
 
+// ClearCookies deletes all cookies of the context.
 func (b *BrowserContext) ClearCookies() error {
 	_, err := b.channel.Send("clearCookies")
 	return err
 }
 
+// GrantPermissions grants the permissions, e.g. "geolocation",
+// "notifications", "clipboard-read" or "camera", to the pages of the context
+// without a prompt. The Origin option limits them to one origin.
 func (b *BrowserContext) GrantPermissions(permissions []string, options ...BrowserContextGrantPermissionsOptions) error {
 	_, err := b.channel.Send("grantPermissions", map[string]interface{}{
 		"permissions": permissions,
@@ -78,24 +177,34 @@ func (b *BrowserContext) GrantPermissions(permissions []string, options ...Brows
 	return err
 }
 
+// ClearPermissions revokes the permissions of GrantPermissions and of the
+// Permissions option of the context.
 func (b *BrowserContext) ClearPermissions() error {
 	_, err := b.channel.Send("clearPermissions")
 	return err
 }
 
 type SetGeolocationOptions struct {
-	Longitude int  `json:"longitude"`
-	Latitude  int  `json:"latitude"`
-	Accuracy  *int `json:"accuracy"`
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+	Accuracy  *int    `json:"accuracy"`
 }
 
+// SetGeolocation changes the emulated position of the pages, e.g. to simulate
+// a moving device. Reading it needs the "geolocation" permission, nil stops
+// the emulation.
 func (b *BrowserContext) SetGeolocation(gelocation *SetGeolocationOptions) error {
-	_, err := b.channel.Send("setGeolocation", map[string]interface{}{
-		"geolocation": gelocation,
-	})
+	params := map[string]interface{}{}
+	if gelocation != nil {
+		params["geolocation"] = gelocation
+	}
+	_, err := b.channel.Send("setGeolocation", params)
 	return err
 }
 
+// SetExtraHTTPHeaders sets headers which are sent with every request of the
+// pages of the context, replacing the ExtraHTTPHeaders option of NewContext.
+// Headers of Page.SetExtraHTTPHeaders take priority over them.
 func (b *BrowserContext) SetExtraHTTPHeaders(headers map[string]string) error {
 	_, err := b.channel.Send("setExtraHTTPHeaders", map[string]interface{}{
 		"headers": serializeHeaders(headers),
@@ -103,6 +212,8 @@ func (b *BrowserContext) SetExtraHTTPHeaders(headers map[string]string) error {
 	return err
 }
 
+// SetOffline emulates the network being offline for all pages of the
+// context, like the Offline option of NewContext.
 func (b *BrowserContext) SetOffline(offline bool) error {
 	_, err := b.channel.Send("setOffline", map[string]interface{}{
 		"offline": offline,
@@ -111,65 +222,314 @@ func (b *BrowserContext) SetOffline(offline bool) error {
 }
 
 type BrowserContextAddInitScriptOptions struct {
+	// Path of a JavaScript file, it takes priority over Script.
 	Path   *string
 	Script *string
 }
 
-func (b *BrowserContext) AddInitScript(options BrowserContextAddInitScriptOptions) error {
-	var source string
-	if options.Script != nil {
-		source = *options.Script
-	}
+// getInitScriptSource returns the Script option or the content of the Path
+// option, which is named after the file in stack traces.
+func getInitScriptSource(options BrowserContextAddInitScriptOptions) (string, error) {
 	if options.Path != nil {
 		content, err := ioutil.ReadFile(*options.Path)
 		if err != nil {
-			return err
+			return "", err
 		}
-		source = string(content)
+		return string(content) + "\n//# sourceURL=" + strings.ReplaceAll(*options.Path, "\n", ""), nil
 	}
-	_, err := b.channel.Send("addInitScript", map[string]interface{}{
+	if options.Script != nil {
+		return *options.Script, nil
+	}
+	return "", errors.New("either the Script or the Path option is required")
+}
+
+// AddInitScript adds a script which is evaluated in every frame of the pages
+// of the context before any of their scripts, e.g. to stub Math.random.
+func (b *BrowserContext) AddInitScript(options BrowserContextAddInitScriptOptions) error {
+	source, err := getInitScriptSource(options)
+	if err != nil {
+		return err
+	}
+	_, err = b.channel.Send("addInitScript", map[string]interface{}{
 		"source": source,
 	})
 	return err
 }
 
-func (b *BrowserContext) WaitForEvent(event string, predicate ...interface{}) interface{} {
-	evChan := make(chan interface{}, 1)
-	b.Once(event, func(ev ...interface{}) {
-		if len(predicate) == 0 {
-			evChan <- ev[0]
-		} else if len(predicate) == 1 {
-			result := reflect.ValueOf(predicate[0]).Call([]reflect.Value{reflect.ValueOf(ev[0])})
-			if result[0].Bool() {
-				evChan <- ev[0]
+// eventFuture starts waiting for the event for which the Predicate option, a
+// func(payload) bool, returns true. The Timeout option defaults to the one of
+// the context.
+func (b *BrowserContext) eventFuture(event string, options ...BrowserContextWaitForEventOptions) (*EventFuture, time.Duration) {
+	var predicate func(payload interface{}) bool
+	timeout := b.timeoutSettings.Timeout()
+	if len(options) == 1 {
+		if options[0].Predicate != nil {
+			predicateValue := reflect.ValueOf(options[0].Predicate)
+			predicate = func(payload interface{}) bool {
+				result := predicateValue.Call([]reflect.Value{reflect.ValueOf(payload)})
+				return result[0].Bool()
 			}
 		}
+		if options[0].Timeout != nil {
+			timeout = *options[0].Timeout
+		}
+	}
+	return b.EventFuture(event, predicate), time.Duration(timeout) * time.Millisecond
+}
+
+// WaitForEvent waits for the event, e.g. "page", for which the predicate, a
+// func of its payload which returns bool, returns true and returns its
+// payload. It waits forever, see WaitForEventWithOptions for a timeout.
+func (b *BrowserContext) WaitForEvent(event string, predicate ...interface{}) interface{} {
+	options := BrowserContextWaitForEventOptions{
+		Timeout: Int(0),
+	}
+	if len(predicate) == 1 {
+		options.Predicate = predicate[0]
+	}
+	payload, _ := b.WaitForEventWithOptions(event, options)
+	return payload
+}
+
+// WaitForEventWithOptions is WaitForEvent with a timeout, it returns a
+// TimeoutError if the event did not arrive within the Timeout option.
+func (b *BrowserContext) WaitForEventWithOptions(event string, options ...BrowserContextWaitForEventOptions) (interface{}, error) {
+	future, timeout := b.eventFuture(event, options...)
+	return future.Wait(timeout)
+}
+
+// ExpectEvent runs cb and waits for the event which it triggers, see
+// WaitForEventWithOptions.
+func (b *BrowserContext) ExpectEvent(event string, cb func() error, options ...BrowserContextWaitForEventOptions) (interface{}, error) {
+	future, timeout := b.eventFuture(event, options...)
+	if err := cb(); err != nil {
+		future.Cancel()
+		return nil, err
+	}
+	return future.Wait(timeout)
+}
+
+// ExpectPage runs cb and returns the page which it opened in the context,
+// e.g. by clicking a link with target="_blank".
+func (b *BrowserContext) ExpectPage(cb func() error, options ...BrowserContextWaitForEventOptions) (*Page, error) {
+	page, err := b.ExpectEvent("page", cb, options...)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*Page), nil
+}
+
+// ExposeBinding adds the function window[name] to every frame of the pages of
+// the context, which calls the binding with the frame from which it was
+// called. It returns a promise of the result of the binding.
+func (b *BrowserContext) ExposeBinding(name string, binding BindingCallFunction) error {
+	b.pagesMutex.Lock()
+	pages := b.pages
+	b.pagesMutex.Unlock()
+	for _, page := range pages {
+		if page.hasBinding(name) {
+			return fmt.Errorf("function \"%s\" has been already registered in one of the pages", name)
+		}
+	}
+	b.bindingsMu.Lock()
+	if _, ok := b.bindings[name]; ok {
+		b.bindingsMu.Unlock()
+		return fmt.Errorf("function \"%s\" has been already registered", name)
+	}
+	b.bindings[name] = binding
+	b.bindingsMu.Unlock()
+	_, err := b.channel.Send("exposeBinding", map[string]interface{}{
+		"name": name,
+	})
+	if err != nil {
+		b.bindingsMu.Lock()
+		delete(b.bindings, name)
+		b.bindingsMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ExposeFunction is ExposeBinding without the source of the call.
+func (b *BrowserContext) ExposeFunction(name string, binding ExposedFunction) error {
+	return b.ExposeBinding(name, func(source *BindingSource, args ...interface{}) interface{} {
+		return binding(args...)
 	})
-	return <-evChan
 }
 
-func (b *BrowserContext) ExpectEvent(event string, cb func() error) (interface{}, error) {
-	return newExpectWrapper(b.WaitForEvent, []interface{}{event}, cb)
+func (b *BrowserContext) getBinding(name string) BindingCallFunction {
+	b.bindingsMu.Lock()
+	defer b.bindingsMu.Unlock()
+	return b.bindings[name]
+}
+
+func (b *BrowserContext) onBinding(bindingCall *BindingCall) {
+	binding := b.getBinding(bindingCall.initializer["name"].(string))
+	if binding == nil {
+		return
+	}
+	go bindingCall.call(binding)
+}
+
+// Route intercepts the requests of all pages of the context, popups included,
+// whose URL matches the url: a glob pattern string, a *regexp.Regexp or a
+// func(string) bool. Routes of Page.Route take priority over them.
+func (b *BrowserContext) Route(url interface{}, handler routeHandler) error {
+	b.routesMu.Lock()
+	defer b.routesMu.Unlock()
+	if len(b.routes) == 0 {
+		_, err := b.channel.Send("setNetworkInterceptionEnabled", map[string]interface{}{
+			"enabled": true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	b.routes = append(b.routes, newRouteHandlerEntry(newURLMatcher(url), handler))
+	return nil
+}
+
+// Unroute removes all routes of Route with the url, a glob pattern string or
+// a *regexp.Regexp with the same expression. Routes with a func(string) bool
+// url can not be removed, as Go can not compare functions.
+func (b *BrowserContext) Unroute(url interface{}) error {
+	b.routesMu.Lock()
+	defer b.routesMu.Unlock()
+	routes := make([]*routeHandlerEntry, 0)
+	for _, entry := range b.routes {
+		if !entry.isRoutedBy(url) {
+			routes = append(routes, entry)
+		}
+	}
+	removed := len(routes) != len(b.routes)
+	b.routes = routes
+	if removed && len(b.routes) == 0 {
+		_, err := b.channel.Send("setNetworkInterceptionEnabled", map[string]interface{}{
+			"enabled": false,
+		})
+		return err
+	}
+	return nil
+}
+
+// onRoute handles the route with the first matching route of the context, it
+// continues the request if there is none. The handler is called without
+// holding routesMu, so that it can call Unroute.
+func (b *BrowserContext) onRoute(route *Route, request *Request) {
+	var handler routeHandler
+	b.routesMu.Lock()
+	for _, handlerEntry := range b.routes {
+		if handlerEntry.matcher.Match(request.URL()) {
+			handler = handlerEntry.handler
+			break
+		}
+	}
+	b.routesMu.Unlock()
+	if handler != nil {
+		handler(route, request)
+		return
+	}
+	if err := route.Continue(); err != nil {
+		log.Printf("could not continue route: %v", err)
+	}
+}
+
+// NewCDPSession creates a CDP session which is attached to the page, e.g. for
+// the Animation or Performance domains. Only Chromium supports it.
+func (b *BrowserContext) NewCDPSession(page *Page) (*CDPSession, error) {
+	channel, err := b.channel.Send("crNewCDPSession", map[string]interface{}{
+		"page": page.channel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not send message: %w", err)
+	}
+	return fromChannel(channel).(*CDPSession), nil
 }
 
 func (b *BrowserContext) Close() error {
-	_, err := b.channel.Send("close")
+	return b.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but stops waiting once ctx is done.
+func (b *BrowserContext) CloseContext(ctx context.Context) error {
+	_, err := b.channel.SendContext(ctx, "close")
 	return err
 }
 
 func newBrowserContext(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *BrowserContext {
 	bt := &BrowserContext{
-		timeoutSettings: newTimeoutSettings(nil),
+		createdAt: time.Now(),
+		closed:    make(chan struct{}),
+		bindings:  make(map[string]BindingCallFunction),
 	}
 	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
+	bt.timeoutSettings = newTimeoutSettings(bt.connection.timeoutSettings)
 	bt.channel.On("page", func(payload map[string]interface{}) {
 		page := fromChannel(payload["page"]).(*Page)
 		page.browserContext = bt
+		page.timeoutSettings.parent = bt.timeoutSettings
 		bt.pagesMutex.Lock()
 		bt.pages = append(bt.pages, page)
 		bt.pagesMutex.Unlock()
+		page.Once("close", func() {
+			bt.pagesMutex.Lock()
+			defer bt.pagesMutex.Unlock()
+			pages := make([]*Page, 0)
+			for _, other := range bt.pages {
+				if other != page {
+					pages = append(pages, other)
+				}
+			}
+			bt.pages = pages
+		})
 		bt.Emit("page", page)
 	})
+	bt.channel.On("crBackgroundPage", func(params map[string]interface{}) {
+		page := fromChannel(params["page"]).(*Page)
+		page.browserContext = bt
+		page.timeoutSettings.parent = bt.timeoutSettings
+		bt.pagesMutex.Lock()
+		bt.backgroundPages = append(bt.backgroundPages, page)
+		bt.pagesMutex.Unlock()
+		page.Once("close", func() {
+			bt.pagesMutex.Lock()
+			defer bt.pagesMutex.Unlock()
+			pages := make([]*Page, 0)
+			for _, other := range bt.backgroundPages {
+				if other != page {
+					pages = append(pages, other)
+				}
+			}
+			bt.backgroundPages = pages
+		})
+		bt.Emit("backgroundpage", page)
+	})
+	bt.channel.On("crServiceWorker", func(params map[string]interface{}) {
+		worker := fromChannel(params["worker"]).(*Worker)
+		bt.pagesMutex.Lock()
+		bt.serviceWorkers = append(bt.serviceWorkers, worker)
+		bt.pagesMutex.Unlock()
+		worker.Once("close", func() {
+			bt.pagesMutex.Lock()
+			defer bt.pagesMutex.Unlock()
+			workers := make([]*Worker, 0)
+			for _, other := range bt.serviceWorkers {
+				if other != worker {
+					workers = append(workers, other)
+				}
+			}
+			bt.serviceWorkers = workers
+		})
+		bt.Emit("serviceworker", worker)
+	})
+	bt.channel.On("bindingCall", func(params map[string]interface{}) {
+		bt.onBinding(fromChannel(params["binding"]).(*BindingCall))
+	})
+	bt.channel.On("route", func(params map[string]interface{}) {
+		route := fromChannel(params["route"]).(*Route)
+		request := fromChannel(params["request"]).(*Request)
+		go bt.onRoute(route, request)
+	})
 	bt.channel.On("close", func() {
 		if bt.browser != nil {
 			contexts := make([]*BrowserContext, 0)
@@ -182,6 +542,13 @@ func newBrowserContext(parent *ChannelOwner, objectType string, guid string, ini
 			bt.browser.contexts = contexts
 			bt.browser.contextsMu.Unlock()
 		}
+		bt.videosMu.Lock()
+		videos := bt.videos
+		close(bt.closed)
+		bt.videosMu.Unlock()
+		for _, video := range videos {
+			video.finish()
+		}
 		bt.Emit("close")
 	})
 	return bt
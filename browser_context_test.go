@@ -1,7 +1,14 @@
 package playwright
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +32,20 @@ func TestBrowserContextClose(t *testing.T) {
 	require.Equal(t, 0, len(helper.Browser.Contexts()))
 }
 
+func TestBrowserContextCreatedAt(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	before := time.Now()
+	context, err := helper.Browser.NewContext()
+	require.NoError(t, err)
+	defer context.Close()
+	require.False(t, context.CreatedAt().Before(before))
+	require.False(t, context.CreatedAt().After(time.Now()))
+	contexts := helper.Browser.Contexts()
+	contexts[0] = nil
+	require.Equal(t, helper.Context, helper.Browser.Contexts()[0])
+}
+
 func TestBrowserContextOffline(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -61,6 +82,30 @@ func TestBrowserContextSetExtraHTTPHeaders(t *testing.T) {
 	<-intercepted
 }
 
+func TestBrowserContextGrantPermissions(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	getPermission := func(name string) interface{} {
+		state, err := helper.Page.Evaluate(`name => navigator.permissions.query({name}).then(result => result.state)`, name)
+		require.NoError(t, err)
+		return state
+	}
+	require.Equal(t, "prompt", getPermission("geolocation"))
+	require.NoError(t, helper.Context.GrantPermissions([]string{"geolocation"}, BrowserContextGrantPermissionsOptions{
+		Origin: String("http://localhost:1234"),
+	}))
+	require.Equal(t, "prompt", getPermission("geolocation"))
+	require.NoError(t, helper.Context.GrantPermissions([]string{"geolocation"}, BrowserContextGrantPermissionsOptions{
+		Origin: String(helper.server.PREFIX),
+	}))
+	require.Equal(t, "granted", getPermission("geolocation"))
+	require.NoError(t, helper.Context.ClearPermissions())
+	require.Equal(t, "prompt", getPermission("geolocation"))
+	require.Error(t, helper.Context.GrantPermissions([]string{"foo"}))
+}
+
 func TestBrowserContextSetGeolocation(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -82,6 +127,31 @@ func TestBrowserContextSetGeolocation(t *testing.T) {
 	require.NoError(t, helper.Context.ClearPermissions())
 }
 
+func TestBrowserContextSetGeolocationMoving(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Context.GrantPermissions([]string{"geolocation"}))
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	_, err = helper.Page.Evaluate(`() => {
+		window.positions = [];
+		navigator.geolocation.watchPosition(position => {
+			window.positions.push(position.coords.latitude + ':' + position.coords.longitude);
+		});
+	}`)
+	require.NoError(t, err)
+	for _, position := range []SetGeolocationOptions{
+		{Latitude: 52.52, Longitude: 13.405},
+		{Latitude: 48.137, Longitude: 11.576},
+	} {
+		position := position
+		require.NoError(t, helper.Context.SetGeolocation(&position))
+		_, err = helper.Page.WaitForFunction(fmt.Sprintf(`() => window.positions.includes("%v:%v")`, position.Latitude, position.Longitude))
+		require.NoError(t, err)
+	}
+	require.NoError(t, helper.Context.SetGeolocation(nil))
+}
+
 func TestBrowserContextAddCookies(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -119,6 +189,49 @@ func TestBrowserContextAddCookies(t *testing.T) {
 	require.Equal(t, "", cookie)
 }
 
+func TestBrowserContextCookiesFilterURLs(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Context.AddCookies(
+		SetNetworkCookieParam{
+			URL:   String("https://foo.com"),
+			Name:  "doggo",
+			Value: "woofs",
+		},
+		SetNetworkCookieParam{
+			Domain:   String("bar.com"),
+			Path:     String("/"),
+			Name:     "catto",
+			Value:    "purrs",
+			Expires:  Int(2000000000),
+			HttpOnly: Bool(true),
+			Secure:   Bool(true),
+			SameSite: String("Lax"),
+		},
+	))
+	cookies, err := helper.Context.Cookies("https://bar.com")
+	require.NoError(t, err)
+	require.Equal(t, []*NetworkCookie{
+		{
+			Name:     "catto",
+			Value:    "purrs",
+			Domain:   "bar.com",
+			Path:     "/",
+			Expires:  2000000000,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: "Lax",
+		},
+	}, cookies)
+	cookies, err = helper.Context.Cookies("https://foo.com", "https://bar.com")
+	require.NoError(t, err)
+	require.Len(t, cookies, 2)
+	require.NoError(t, helper.Context.ClearCookies())
+	cookies, err = helper.Context.Cookies()
+	require.NoError(t, err)
+	require.Len(t, cookies, 0)
+}
+
 func TestBrowserContextAddInitScript(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -132,6 +245,31 @@ func TestBrowserContextAddInitScript(t *testing.T) {
 	require.Equal(t, 123, result)
 }
 
+func TestBrowserContextAddInitScriptFrames(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Context.AddInitScript(BrowserContextAddInitScriptOptions{
+		Script: String(`Math.random = () => 42;`),
+	}))
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	_, err = helper.Page.Evaluate(`url => new Promise(resolve => {
+		const frame = document.createElement('iframe');
+		frame.src = url;
+		frame.onload = resolve;
+		document.body.appendChild(frame);
+	})`, helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	frames := helper.Page.Frames()
+	require.Len(t, frames, 2)
+	for _, frame := range frames {
+		result, err := frame.Evaluate(`() => Math.random()`)
+		require.NoError(t, err)
+		require.Equal(t, 42, result)
+	}
+	require.Error(t, helper.Context.AddInitScript(BrowserContextAddInitScriptOptions{}))
+}
+
 func TestBrowserContextAddInitScriptWithPath(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -144,3 +282,596 @@ func TestBrowserContextAddInitScriptWithPath(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 123, result)
 }
+
+func TestBrowserContextViewportAndDeviceScaleFactor(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		Viewport: &BrowserNewContextViewport{
+			Width:  Int(456),
+			Height: Int(789),
+		},
+		DeviceScaleFactor: Int(2),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	helper.utils.VerifyViewport(t, page, 456, 789)
+	helper.utils.AssertEval(t, page, "window.devicePixelRatio", 2)
+}
+
+func TestBrowserContextNoViewport(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		NoViewport: Bool(true),
+		Viewport: &BrowserNewContextViewport{
+			Width:  Int(456),
+			Height: Int(789),
+		},
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	require.Equal(t, ViewportSize{}, page.ViewportSize())
+	width, err := page.Evaluate("window.innerWidth")
+	require.NoError(t, err)
+	require.NotEqual(t, 456, width)
+}
+
+func TestBrowserContextUserAgent(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		UserAgent: String("foobar"),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	request := helper.server.WaitForRequestChan("/empty.html")
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", (<-request).UserAgent())
+	helper.utils.AssertEval(t, page, "navigator.userAgent", "foobar")
+}
+
+func TestBrowserContextLocaleAndTimezoneId(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		Locale:     String("de-DE"),
+		TimezoneId: String("America/Jamaica"),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	request := helper.server.WaitForRequestChan("/empty.html")
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix((<-request).Header.Get("Accept-Language"), "de-DE"))
+	helper.utils.AssertEval(t, page, "navigator.language", "de-DE")
+	helper.utils.AssertEval(t, page, "(1000000.5).toLocaleString()", "1.000.000,5")
+	helper.utils.AssertEval(t, page, "Intl.DateTimeFormat().resolvedOptions().timeZone", "America/Jamaica")
+
+	_, err = helper.Browser.NewContext(BrowserNewContextOptions{
+		TimezoneId: String("Foo/Bar"),
+	})
+	require.Error(t, err)
+}
+
+func TestBrowserContextGeolocationOption(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		Geolocation: &BrowserNewContextGeolocation{
+			Latitude:  Float(52.52),
+			Longitude: Float(13.4),
+			Accuracy:  Int(10),
+		},
+		Permissions: []string{"geolocation"},
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	geolocation, err := page.Evaluate(`() => new Promise(resolve => navigator.geolocation.getCurrentPosition(position => {
+      resolve({latitude: position.coords.latitude, longitude: position.coords.longitude, accuracy: position.coords.accuracy});
+    }))`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"latitude":  52.52,
+		"longitude": 13.4,
+		"accuracy":  10,
+	}, geolocation)
+}
+
+func TestBrowserContextExtraHTTPHeadersOption(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		ExtraHTTPHeaders: map[string]string{
+			"Authorization": "Bearer foo",
+		},
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	request := helper.server.WaitForRequestChan("/empty.html")
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer foo", (<-request).Header.Get("Authorization"))
+
+	require.NoError(t, context.SetExtraHTTPHeaders(map[string]string{
+		"Authorization": "Bearer bar",
+	}))
+	request = helper.server.WaitForRequestChan("/empty.html")
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer bar", (<-request).Header.Get("Authorization"))
+}
+
+func TestBrowserContextHttpCredentials(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	helper.server.SetRoute("/protected.html", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Secure Area"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte("Playwright"))
+		require.NoError(t, err)
+	})
+	response, err := helper.Page.Goto(helper.server.PREFIX + "/protected.html")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.Status())
+
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		HttpCredentials: &BrowserNewContextHttpCredentials{
+			Username: String("user"),
+			Password: String("pass"),
+		},
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	response, err = page.Goto(helper.server.PREFIX + "/protected.html")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.Status())
+}
+
+func TestBrowserContextOfflineOption(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		Offline: Bool(true),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.Error(t, err)
+	helper.utils.AssertEval(t, page, "window.navigator.onLine", false)
+
+	require.NoError(t, context.SetOffline(false))
+	response, err := page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.Status())
+}
+
+func TestBrowserContextSetOfflineEvents(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	_, err = helper.Page.Evaluate(`() => {
+		window.events = [];
+		window.addEventListener('offline', () => window.events.push('offline'));
+		window.addEventListener('online', () => window.events.push('online'));
+	}`)
+	require.NoError(t, err)
+	require.NoError(t, helper.Context.SetOffline(true))
+	_, err = helper.Page.WaitForFunction(`() => window.events.includes('offline')`)
+	require.NoError(t, err)
+	_, err = helper.Page.Evaluate(`url => fetch(url)`, helper.server.EMPTY_PAGE)
+	require.Error(t, err)
+	require.NoError(t, helper.Context.SetOffline(false))
+	_, err = helper.Page.WaitForFunction(`() => window.events.join() === 'offline,online'`)
+	require.NoError(t, err)
+	_, err = helper.Page.Evaluate(`url => fetch(url).then(response => response.status)`, helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+}
+
+func TestBrowserContextIgnoreHTTPSErrors(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("secure"))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+	_, err := helper.Page.Goto(server.URL)
+	require.Error(t, err)
+
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		IgnoreHTTPSErrors: Bool(true),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	response, err := page.Goto(server.URL)
+	require.NoError(t, err)
+	require.True(t, response.Ok())
+}
+
+func TestBrowserContextBypassCSP(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	helper.server.SetRoute("/csp.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		_, err := w.Write([]byte("<html></html>"))
+		require.NoError(t, err)
+	})
+	_, err := helper.Page.Goto(helper.server.PREFIX + "/csp.html")
+	require.NoError(t, err)
+	_, err = helper.Page.AddScriptTag(PageAddScriptTagOptions{
+		Content: String("window.__injected = 42;"),
+	})
+	require.Error(t, err)
+	helper.utils.AssertEval(t, helper.Page, "window.__injected", nil)
+
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		BypassCSP: Bool(true),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.PREFIX + "/csp.html")
+	require.NoError(t, err)
+	_, err = page.AddScriptTag(PageAddScriptTagOptions{
+		Content: String("window.__injected = 42;"),
+	})
+	require.NoError(t, err)
+	helper.utils.AssertEval(t, page, "window.__injected", 42)
+}
+
+func TestBrowserContextJavaScriptEnabled(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		JavaScriptEnabled: Bool(false),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	require.NoError(t, page.SetContent(`<script>var something = "forbidden"</script><noscript>no js</noscript>`))
+	helper.utils.AssertEval(t, page, "typeof window.something", "undefined")
+
+	page2, err := helper.Browser.NewPage()
+	require.NoError(t, err)
+	defer page2.Close()
+	require.NoError(t, page2.SetContent(`<script>var something = "forbidden"</script>`))
+	helper.utils.AssertEval(t, page2, "window.something", "forbidden")
+}
+
+func TestBrowserContextExposeFunction(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Context.ExposeFunction("add", func(args ...interface{}) interface{} {
+		return args[0].(int) + args[1].(int)
+	}))
+	require.NoError(t, helper.Page.ExposeFunction("mul", func(args ...interface{}) interface{} {
+		return args[0].(int) * args[1].(int)
+	}))
+	helper.utils.AssertEval(t, helper.Page, "add(5, 6)", 11)
+	helper.utils.AssertEval(t, helper.Page, "mul(5, 6)", 30)
+	require.Error(t, helper.Page.ExposeFunction("add", func(args ...interface{}) interface{} {
+		return nil
+	}))
+	require.Error(t, helper.Context.ExposeFunction("mul", func(args ...interface{}) interface{} {
+		return nil
+	}))
+}
+
+func TestBrowserContextRoute(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	intercepted := make(chan string, 10)
+	contextHandler := func(route *Route, request *Request) {
+		intercepted <- "context"
+		require.NoError(t, route.Fulfill(RouteFulfillOptions{
+			Body: "context",
+		}))
+	}
+	require.NoError(t, helper.Context.Route(regexp.MustCompile(`empty\.html$`), contextHandler))
+	response, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	body, err := response.Body()
+	require.NoError(t, err)
+	require.Equal(t, "context", string(body))
+	require.Equal(t, "context", <-intercepted)
+
+	// the routes of the page take priority
+	require.NoError(t, helper.Page.Route(func(url string) bool {
+		return strings.HasSuffix(url, "empty.html")
+	}, func(route *Route, request *Request) {
+		intercepted <- "page"
+		require.NoError(t, route.Continue())
+	}))
+	_, err = helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.Equal(t, "page", <-intercepted)
+
+	// popups are routed by the context
+	popup, err := helper.Context.ExpectEvent("page", func() error {
+		_, err := helper.Page.Evaluate(`url => window.open(url)`, helper.server.EMPTY_PAGE)
+		return err
+	})
+	require.NoError(t, err)
+	popup.(*Page).WaitForLoadState()
+	require.Equal(t, "context", <-intercepted)
+
+	require.NoError(t, helper.Context.Unroute(regexp.MustCompile(`empty\.html$`)))
+	page, err := helper.Context.NewPage()
+	require.NoError(t, err)
+	response, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	body, err = response.Body()
+	require.NoError(t, err)
+	require.NotEqual(t, "context", string(body))
+	require.Len(t, intercepted, 0)
+}
+
+func TestBrowserContextUnrouteInRouteHandler(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "BrowserContext",
+			"guid":        "BrowserContext",
+			"initializer": map[string]interface{}{},
+		},
+	})
+	context := fromChannel(connection.objects["BrowserContext"].channel).(*BrowserContext)
+	intercepted := make(chan interface{}, 1)
+	go func() {
+		message := <-fake.sent
+		intercepted <- message["params"]
+		failed := &Message{ID: message["id"].(int)}
+		failed.Error = &struct {
+			Error errorPayload `json:"error"`
+		}{Error: errorPayload{Name: "Error", Message: "Target closed"}}
+		connection.Dispatch(failed)
+		for i := 0; i < 2; i++ {
+			message = <-fake.sent
+			intercepted <- message["params"]
+			connection.Dispatch(&Message{ID: message["id"].(int)})
+		}
+	}()
+	handled := make(chan error, 1)
+	handler := func(route *Route, request *Request) {
+		handled <- context.Unroute("**/*")
+	}
+	// a failed route is not added
+	require.Error(t, context.Route("**/*", handler))
+	require.Equal(t, map[string]interface{}{"enabled": true}, <-intercepted)
+	require.Empty(t, context.routes)
+	require.NoError(t, context.Route("**/*", handler))
+	require.Equal(t, map[string]interface{}{"enabled": true}, <-intercepted)
+
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Request",
+			"guid":        "Request",
+			"initializer": map[string]interface{}{"url": "https://example.com/"},
+		},
+	})
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Route",
+			"guid":        "Route",
+			"initializer": map[string]interface{}{"request": map[string]interface{}{"guid": "Request"}},
+		},
+	})
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "route",
+		Params: map[string]interface{}{
+			"route":   map[string]interface{}{"guid": "Route"},
+			"request": map[string]interface{}{"guid": "Request"},
+		},
+	})
+	select {
+	case err := <-handled:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Unroute in the route handler deadlocked")
+	}
+	require.Equal(t, map[string]interface{}{"enabled": false}, <-intercepted)
+	require.Empty(t, context.routes)
+}
+
+func TestBrowserContextWaitForEvent(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	_, err := helper.Context.WaitForEventWithOptions("page", BrowserContextWaitForEventOptions{
+		Timeout: Int(50),
+	})
+	require.True(t, errors.Is(err, ErrTimeout))
+
+	opened := make(chan error, 1)
+	go func() {
+		for i := 0; i < 2; i++ {
+			if _, err := helper.Page.Evaluate(`url => window.open(url)`, fmt.Sprintf("%s?popup=%d", helper.server.EMPTY_PAGE, i)); err != nil {
+				opened <- err
+				return
+			}
+		}
+		opened <- nil
+	}()
+	pages := 0
+	page, err := helper.Context.WaitForEventWithOptions("page", BrowserContextWaitForEventOptions{
+		Predicate: func(page *Page) bool {
+			pages++
+			return pages == 2
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-opened)
+	page.(*Page).WaitForLoadState()
+	require.Equal(t, helper.server.EMPTY_PAGE+"?popup=1", page.(*Page).URL())
+
+	go func() {
+		_, err := helper.Page.Evaluate(`url => window.open(url)`, helper.server.EMPTY_PAGE)
+		opened <- err
+	}()
+	page = helper.Context.WaitForEvent("page", func(page *Page) bool {
+		return true
+	})
+	require.NoError(t, <-opened)
+	require.IsType(t, &Page{}, page)
+}
+
+func TestBrowserContextExpectPage(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.NoError(t, helper.Page.SetContent(`<a target="_blank" href="/one-style.html">link</a>`))
+	page, err := helper.Context.ExpectPage(func() error {
+		return helper.Page.Click("a")
+	})
+	require.NoError(t, err)
+	page.WaitForLoadState()
+	require.Equal(t, helper.server.PREFIX+"/one-style.html", page.URL())
+
+	_, err = helper.Context.ExpectPage(func() error {
+		return errors.New("no click")
+	})
+	require.EqualError(t, err, "no click")
+}
+
+func TestBrowserContextPagesAndOnPage(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.Equal(t, []*Page{helper.Page}, helper.Context.Pages())
+	popups := make(chan *Page, 1)
+	helper.Context.OnPage(func(page *Page) {
+		popups <- page
+	})
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.NoError(t, helper.Page.SetContent(`<a target="_blank" href="/one-style.html">link</a>`))
+	require.NoError(t, helper.Page.Click("a"))
+	popup := <-popups
+	require.Equal(t, []*Page{helper.Page, popup}, helper.Context.Pages())
+
+	pages := helper.Context.Pages()
+	pages[0] = nil
+	require.Equal(t, helper.Page, helper.Context.Pages()[0])
+
+	closed := make(chan bool, 1)
+	popup.Once("close", func() {
+		closed <- true
+	})
+	require.NoError(t, popup.Close())
+	<-closed
+	require.Equal(t, []*Page{helper.Page}, helper.Context.Pages())
+}
+
+func TestBrowserContextNewCDPSession(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	if !helper.IsChromium {
+		t.Skip("CDP sessions are only supported by Chromium")
+	}
+	session, err := helper.Context.NewCDPSession(helper.Page)
+	require.NoError(t, err)
+	requests := make(chan string, 10)
+	session.OnEvent("Network.requestWillBeSent", func(params map[string]interface{}) {
+		requests <- params["request"].(map[string]interface{})["url"].(string)
+	})
+	_, err = session.Send("Network.enable", nil)
+	require.NoError(t, err)
+	_, err = helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.Equal(t, helper.server.EMPTY_PAGE, <-requests)
+	result, err := session.Send("Runtime.evaluate", map[string]interface{}{
+		"expression":    "1 + 2",
+		"returnByValue": true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, float64(3), result.(map[string]interface{})["result"].(map[string]interface{})["value"])
+	require.NoError(t, session.Detach())
+}
+
+func TestBrowserContextBackgroundPagesAndServiceWorkers(t *testing.T) {
+	connection, _ := newFakeConnection()
+	create := func(parent, objectType, guid string, initializer map[string]interface{}) {
+		connection.Dispatch(&Message{
+			GUID:   parent,
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type":        objectType,
+				"guid":        guid,
+				"initializer": initializer,
+			},
+		})
+	}
+	create("", "BrowserContext", "BrowserContext", map[string]interface{}{})
+	context := fromChannel(connection.objects["BrowserContext"].channel).(*BrowserContext)
+	backgroundPages := make(chan *Page, 1)
+	context.On("backgroundpage", func(page *Page) {
+		backgroundPages <- page
+	})
+	serviceWorkers := make(chan *Worker, 1)
+	context.On("serviceworker", func(worker *Worker) {
+		serviceWorkers <- worker
+	})
+
+	create("BrowserContext", "Frame", "Frame", map[string]interface{}{"name": "", "url": "about:blank"})
+	create("BrowserContext", "Page", "Page", map[string]interface{}{
+		"mainFrame": map[string]interface{}{"guid": "Frame"},
+	})
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "crBackgroundPage",
+		Params: map[string]interface{}{"page": map[string]interface{}{"guid": "Page"}},
+	})
+	page := <-backgroundPages
+	require.Equal(t, []*Page{page}, context.BackgroundPages())
+	require.Equal(t, context, page.Context())
+	require.Empty(t, context.Pages())
+
+	create("BrowserContext", "Worker", "Worker", map[string]interface{}{"url": "https://example.com/sw.js"})
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "crServiceWorker",
+		Params: map[string]interface{}{"worker": map[string]interface{}{"guid": "Worker"}},
+	})
+	worker := <-serviceWorkers
+	require.Equal(t, "https://example.com/sw.js", worker.URL())
+	require.Equal(t, []*Worker{worker}, context.ServiceWorkers())
+
+	connection.Dispatch(&Message{GUID: "Worker", Method: "close"})
+	require.Empty(t, context.ServiceWorkers())
+	connection.Dispatch(&Message{GUID: "Page", Method: "close"})
+	require.Empty(t, context.BackgroundPages())
+}
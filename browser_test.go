@@ -1,7 +1,11 @@
 package playwright
 
 import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -18,6 +22,13 @@ func TestBrowserVersion(t *testing.T) {
 	require.Greater(t, len(helper.Browser.Version()), 2)
 }
 
+func TestBrowserBrowserType(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.Equal(t, helper.BrowserType, helper.Browser.BrowserType())
+	require.Contains(t, []string{"chromium", "firefox", "webkit"}, helper.Browser.BrowserType().Name())
+}
+
 func TestBrowserNewContext(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -43,3 +54,168 @@ func TestBrowserClose(t *testing.T) {
 	require.NoError(t, browser.Close())
 	require.NoError(t, pw.Stop())
 }
+
+func TestPlaywrightStopClosesBrowsers(t *testing.T) {
+	pw, err := Run()
+	require.NoError(t, err)
+	browser, err := pw.Chromium.Launch()
+	require.NoError(t, err)
+	require.True(t, browser.IsConnected)
+	require.NoError(t, pw.Stop())
+	require.False(t, browser.IsConnected)
+}
+
+func TestRunMultipleInstances(t *testing.T) {
+	instances := make([]*Playwright, 2)
+	errs := make(chan error, len(instances))
+	for i := range instances {
+		go func(i int) {
+			var err error
+			instances[i], err = Run()
+			errs <- err
+		}(i)
+	}
+	for range instances {
+		require.NoError(t, <-errs)
+	}
+	require.NotSame(t, instances[0].connection, instances[1].connection)
+	browser, err := instances[0].Chromium.Launch()
+	require.NoError(t, err)
+	require.NoError(t, instances[1].Stop())
+	page, err := browser.NewPage()
+	require.NoError(t, err)
+	_, err = page.Evaluate("1 + 1")
+	require.NoError(t, err)
+	require.NoError(t, instances[0].Stop())
+}
+
+func TestBrowserDisconnectedOnConnectionLoss(t *testing.T) {
+	connection, _ := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Browser",
+			"guid":        "Browser@1",
+			"initializer": map[string]interface{}{"version": "1.0"},
+		},
+	})
+	browser := fromChannel(connection.objects["Browser@1"].channel).(*Browser)
+	disconnected := make(chan *Browser, 2)
+	browser.On("disconnected", func(browser *Browser) {
+		disconnected <- browser
+	})
+	connection.transportClosed(errors.New("connection reset"))
+	require.Equal(t, browser, <-disconnected)
+	require.False(t, browser.IsConnected)
+	browser.channel.Emit("close")
+	select {
+	case <-disconnected:
+		t.Fatal("disconnected was emitted twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestBrowserCloseRemovesConnectionHandler(t *testing.T) {
+	connection, _ := newFakeConnection()
+	for _, guid := range []string{"Browser@1", "Browser@2"} {
+		connection.Dispatch(&Message{
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type":        "Browser",
+				"guid":        guid,
+				"initializer": map[string]interface{}{"version": "1.0"},
+			},
+		})
+	}
+	require.Len(t, connection.closeHandlers, 2)
+	connection.Dispatch(&Message{GUID: "Browser@1", Method: "close"})
+	require.Len(t, connection.closeHandlers, 1)
+	browser := fromChannel(connection.objects["Browser@2"].channel).(*Browser)
+	require.Equal(t, browser.connectionClosed, connection.closeHandlers[0])
+}
+
+func TestBrowserNewBrowserCDPSession(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	if !helper.IsChromium {
+		t.Skip("CDP sessions are only supported by Chromium")
+	}
+	session, err := helper.Browser.NewBrowserCDPSession()
+	require.NoError(t, err)
+	version, err := session.Send("Browser.getVersion", nil)
+	require.NoError(t, err)
+	require.Contains(t, version.(map[string]interface{})["product"], "Chrome")
+
+	targetCreated := make(chan map[string]interface{}, 1)
+	session.Once("Target.targetCreated", func(params map[string]interface{}) {
+		targetCreated <- params
+	})
+	_, err = session.Send("Target.setDiscoverTargets", map[string]interface{}{"discover": true})
+	require.NoError(t, err)
+	page, err := helper.Context.NewPage()
+	require.NoError(t, err)
+	require.NotNil(t, (<-targetCreated)["targetInfo"])
+	require.NoError(t, page.Close())
+	require.NoError(t, session.Detach())
+}
+
+func TestBrowserCloseReason(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Browser",
+			"guid":        "Browser@1",
+			"initializer": map[string]interface{}{"version": "1.0"},
+		},
+	})
+	connection.Dispatch(&Message{
+		GUID:   "Browser@1",
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "BrowserContext",
+			"guid":        "BrowserContext@1",
+			"initializer": map[string]interface{}{},
+		},
+	})
+	browser := fromChannel(connection.objects["Browser@1"].channel).(*Browser)
+	context := fromChannel(connection.objects["BrowserContext@1"].channel).(*BrowserContext)
+	go func() {
+		message := <-fake.sent
+		connection.Dispatch(&Message{ID: message["id"].(int)})
+		message = <-fake.sent
+		closed := &Message{ID: message["id"].(int)}
+		closed.Error = &struct {
+			Error errorPayload `json:"error"`
+		}{Error: errorPayload{Name: "Error", Message: "Target closed"}}
+		connection.Dispatch(closed)
+	}()
+	require.NoError(t, browser.Close(BrowserCloseOptions{Reason: String("shutting down")}))
+	_, err := context.channel.Send("newPage")
+	var targetClosed *TargetClosedError
+	require.True(t, errors.As(err, &targetClosed))
+	require.Equal(t, "Target closed: shutting down", targetClosed.Message)
+}
+
+func TestBrowserTracing(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	if !helper.IsChromium {
+		t.Skip("tracing is only supported by Chromium")
+	}
+	tracePath := filepath.Join(t.TempDir(), "trace.json")
+	require.NoError(t, helper.Browser.StartTracing(ChromiumBrowserStartTracingOptions{
+		Page:        helper.Page,
+		Path:        String(tracePath),
+		Screenshots: Bool(true),
+		Categories:  []string{"devtools.timeline"},
+	}))
+	_, err := helper.Page.Goto(helper.server.PREFIX + "/grid.html")
+	require.NoError(t, err)
+	trace, err := helper.Browser.StopTracing()
+	require.NoError(t, err)
+	require.Contains(t, string(trace), "traceEvents")
+	written, err := ioutil.ReadFile(tracePath)
+	require.NoError(t, err)
+	require.Equal(t, trace, written)
+}
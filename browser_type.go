@@ -1,41 +1,331 @@
 package playwright
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 type BrowserType struct {
 	ChannelOwner
+	installBrowser func(name string) error
+	installOnce    sync.Once
+	installErr     error
+	browsers       []*Browser
+	// persistentContexts are the open contexts of LaunchPersistentContext
+	persistentContexts []*BrowserContext
+	browsersMu         sync.Mutex
+}
+
+// ensureInstalled installs the browser before its first launch if it was not
+// installed by Run already. A browser with a custom executablePath does not
+// need the downloaded one.
+func (b *BrowserType) ensureInstalled(executablePath *string) error {
+	if b.installBrowser == nil || executablePath != nil {
+		return nil
+	}
+	b.installOnce.Do(func() {
+		b.installErr = b.installBrowser(b.Name())
+	})
+	return b.installErr
 }
 
+// Name returns the name of the browser engine: "chromium", "firefox" or
+// "webkit".
 func (b *BrowserType) Name() string {
 	return b.initializer["name"].(string)
 }
 
+// ExecutablePath returns the path of the downloaded browser which Launch
+// executes unless the ExecutablePath or Channel option is given. It is empty
+// if the driver does not know a path for the browser on this platform.
 func (b *BrowserType) ExecutablePath() string {
-	return b.initializer["executablePath"].(string)
+	executablePath, _ := b.initializer["executablePath"].(string)
+	return executablePath
 }
 
+// Launch launches a new browser. With the ExecutablePath option it uses the
+// given binary, e.g. a Chromium of the system, instead of the downloaded one.
+// The Channel option of Chromium selects an installed branded browser like
+// Google Chrome or Microsoft Edge.
 func (b *BrowserType) Launch(options ...BrowserTypeLaunchOptions) (*Browser, error) {
-	channel, err := b.channel.Send("launch", options)
+	return b.LaunchContext(context.Background(), options...)
+}
+
+// LaunchContext is like Launch but stops waiting once ctx is done.
+func (b *BrowserType) LaunchContext(ctx context.Context, options ...BrowserTypeLaunchOptions) (*Browser, error) {
+	var executablePath *string
+	if len(options) == 1 {
+		option := options[0]
+		if err := checkIgnoreDefaultArgs(option.IgnoreDefaultArgs); err != nil {
+			return nil, err
+		}
+		var err error
+		executablePath, err = b.channelExecutablePath(option.Channel, option.ExecutablePath)
+		if err != nil {
+			return nil, err
+		}
+		option.ExecutablePath = executablePath
+		option.Headless, option.Args, err = b.headlessModeArgs(option.HeadlessMode, option.Headless, option.Args, executablePath)
+		if err != nil {
+			return nil, err
+		}
+		options = []BrowserTypeLaunchOptions{option}
+	}
+	if err := b.ensureInstalled(executablePath); err != nil {
+		return nil, err
+	}
+	overrides := map[string]interface{}{}
+	if len(options) == 1 && options[0].Env != nil {
+		overrides["env"] = serializeEnv(options[0].Env)
+	}
+	channel, err := b.channel.SendContext(ctx, "launch", options, overrides)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message: %w", err)
 	}
-	return fromChannel(channel).(*Browser), nil
+	browser := fromChannel(channel).(*Browser)
+	if len(options) == 1 && options[0].VideosPath != nil {
+		browser.videosPath = *options[0].VideosPath
+	}
+	b.addBrowser(browser)
+	return browser, nil
+}
+
+func (b *BrowserType) addBrowser(browser *Browser) {
+	browser.browserType = b
+	b.browsersMu.Lock()
+	b.browsers = append(b.browsers, browser)
+	b.browsersMu.Unlock()
+}
+
+func (b *BrowserType) removeBrowser(browser *Browser) {
+	b.browsersMu.Lock()
+	defer b.browsersMu.Unlock()
+	browsers := make([]*Browser, 0)
+	for _, other := range b.browsers {
+		if other != browser {
+			browsers = append(browsers, other)
+		}
+	}
+	b.browsers = browsers
+}
+
+func (b *BrowserType) removePersistentContext(context *BrowserContext) {
+	b.browsersMu.Lock()
+	defer b.browsersMu.Unlock()
+	contexts := make([]*BrowserContext, 0)
+	for _, other := range b.persistentContexts {
+		if other != context {
+			contexts = append(contexts, other)
+		}
+	}
+	b.persistentContexts = contexts
+}
+
+// serializeEnv converts the Env launch option into the name value pairs of
+// the protocol, the values are formatted like fmt.Sprint does.
+func serializeEnv(env map[string]interface{}) []map[string]string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	serialized := make([]map[string]string, 0, len(env))
+	for _, name := range names {
+		serialized = append(serialized, map[string]string{
+			"name":  name,
+			"value": fmt.Sprint(env[name]),
+		})
+	}
+	return serialized
+}
+
+// headlessModeArgs applies the HeadlessMode launch option. The driver only
+// knows the old headless mode, so the new one launches a headed Chromium with
+// the --headless=new argument. The bundled Chromium ignores that argument, so
+// the new mode needs the executablePath of a Channel or the ExecutablePath
+// option.
+func (b *BrowserType) headlessModeArgs(mode *string, headless *bool, args []string, executablePath *string) (*bool, []string, error) {
+	if mode == nil {
+		return headless, args, nil
+	}
+	if b.Name() != "chromium" {
+		return nil, nil, fmt.Errorf("the headless mode can only be selected for chromium, not for %s", b.Name())
+	}
+	if headless != nil && !*headless {
+		return nil, nil, fmt.Errorf("the HeadlessMode option requires a headless browser")
+	}
+	switch *mode {
+	case "old":
+		return Bool(true), args, nil
+	case "new":
+		if executablePath == nil {
+			return nil, nil, fmt.Errorf("the new headless mode needs a Chrome 109 or newer of the Channel or ExecutablePath option, the bundled Chromium does not support it")
+		}
+		return Bool(false), append(append([]string{}, args...), "--headless=new"), nil
+	}
+	return nil, nil, fmt.Errorf("unknown headless mode %q, it is either \"new\" or \"old\"", *mode)
 }
 
+// checkIgnoreDefaultArgs verifies the IgnoreDefaultArgs option, which is
+// either true to launch without any default args or the list of the default
+// args to leave out, e.g. []string{"--mute-audio"}.
+func checkIgnoreDefaultArgs(ignoreDefaultArgs interface{}) error {
+	switch ignoreDefaultArgs.(type) {
+	case nil, bool, []string:
+		return nil
+	}
+	return fmt.Errorf("IgnoreDefaultArgs must be a bool or a []string, got %T", ignoreDefaultArgs)
+}
+
+// closeBrowsers closes all browsers and persistent contexts which were
+// launched by this BrowserType and are still open.
+func (b *BrowserType) closeBrowsers() error {
+	b.browsersMu.Lock()
+	browsers := make([]*Browser, len(b.browsers))
+	copy(browsers, b.browsers)
+	contexts := make([]*BrowserContext, len(b.persistentContexts))
+	copy(contexts, b.persistentContexts)
+	b.browsersMu.Unlock()
+	for _, context := range contexts {
+		// the browser writes the profile to the user data dir on close
+		if err := context.Close(); err != nil {
+			return fmt.Errorf("could not close persistent context: %w", err)
+		}
+	}
+	for _, browser := range browsers {
+		if !browser.IsConnected {
+			continue
+		}
+		if err := browser.Close(); err != nil {
+			return fmt.Errorf("could not close browser: %w", err)
+		}
+	}
+	return nil
+}
+
+// LaunchPersistentContext launches a browser which uses userDataDir as its
+// profile, so that cookies, local storage and extensions are kept between
+// runs. The browser is closed with the returned context.
 func (b *BrowserType) LaunchPersistentContext(userDataDir string, options ...BrowserTypeLaunchPersistentContextOptions) (*BrowserContext, error) {
+	return b.LaunchPersistentContextContext(context.Background(), userDataDir, options...)
+}
+
+// LaunchPersistentContextContext is like LaunchPersistentContext but stops
+// waiting once ctx is done.
+func (b *BrowserType) LaunchPersistentContextContext(ctx context.Context, userDataDir string, options ...BrowserTypeLaunchPersistentContextOptions) (*BrowserContext, error) {
+	var executablePath *string
+	if len(options) == 1 {
+		option := options[0]
+		if err := checkIgnoreDefaultArgs(option.IgnoreDefaultArgs); err != nil {
+			return nil, err
+		}
+		var err error
+		executablePath, err = b.channelExecutablePath(option.Channel, option.ExecutablePath)
+		if err != nil {
+			return nil, err
+		}
+		option.ExecutablePath = executablePath
+		option.Headless, option.Args, err = b.headlessModeArgs(option.HeadlessMode, option.Headless, option.Args, executablePath)
+		if err != nil {
+			return nil, err
+		}
+		options = []BrowserTypeLaunchPersistentContextOptions{option}
+	}
+	if err := b.ensureInstalled(executablePath); err != nil {
+		return nil, err
+	}
 	overrides := map[string]interface{}{
 		"userDataDir": userDataDir,
 	}
 	if len(options) == 1 && options[0].ExtraHTTPHeaders != nil {
 		overrides["extraHTTPHeaders"] = serializeHeaders(options[0].ExtraHTTPHeaders)
 	}
-	channel, err := b.channel.Send("launchPersistentContext", options, overrides)
+	if len(options) == 1 && options[0].Env != nil {
+		overrides["env"] = serializeEnv(options[0].Env)
+	}
+	if len(options) == 1 && options[0].NoViewport != nil && *options[0].NoViewport {
+		overrides["noDefaultViewport"] = true
+		option := options[0]
+		option.Viewport = nil
+		options = []BrowserTypeLaunchPersistentContextOptions{option}
+	}
+	channel, err := b.channel.SendContext(ctx, "launchPersistentContext", options, overrides)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message: %w", err)
 	}
-	return fromChannel(channel).(*BrowserContext), nil
+	browserContext := fromChannel(channel).(*BrowserContext)
+	browserContext.applyDefaultTimeouts()
+	b.browsersMu.Lock()
+	b.persistentContexts = append(b.persistentContexts, browserContext)
+	b.browsersMu.Unlock()
+	browserContext.Once("close", func() {
+		b.removePersistentContext(browserContext)
+	})
+	return browserContext, nil
+}
+
+// Connect attaches to a browser which was launched elsewhere and is served
+// over a WebSocket, e.g. by launchServer of the Node.js library, instead of
+// launching a local one.
+func (b *BrowserType) Connect(wsEndpoint string, options ...BrowserTypeConnectOptions) (*Browser, error) {
+	dialer := *websocket.DefaultDialer
+	header := http.Header{}
+	if len(options) == 1 {
+		option := options[0]
+		if option.Timeout != nil {
+			dialer.HandshakeTimeout = time.Duration(*option.Timeout) * time.Millisecond
+		}
+		if option.TLSConfig != nil {
+			dialer.TLSClientConfig = option.TLSConfig
+		}
+		if option.EnableCompression != nil {
+			dialer.EnableCompression = *option.EnableCompression
+		}
+		for name, value := range option.Headers {
+			header.Set(name, value)
+		}
+	}
+	conn, _, err := dialer.Dial(wsEndpoint, header)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %w", wsEndpoint, err)
+	}
+	connection := newConnection(newWebSocketTransport(conn), func() error {
+		return nil
+	})
+	go func() {
+		connection.transportClosed(connection.Start())
+	}()
+	obj, err := connection.CallOnObjectWithKnownName("remoteBrowser")
+	if err != nil {
+		_ = connection.Stop()
+		return nil, fmt.Errorf("the server at %s did not provide a browser: %w", wsEndpoint, err)
+	}
+	// the server does not slow down the calls of a connected client
+	if len(options) == 1 && options[0].SlowMo != nil {
+		connection.slowMo = time.Duration(*options[0].SlowMo) * time.Millisecond
+	}
+	browser := fromChannel(obj.(*remoteBrowser).initializer["browser"]).(*Browser)
+	browser.isRemote = true
+	browser.browserType = b
+	return browser, nil
+}
+
+// remoteBrowser is the root object of a browser server, it holds the Browser
+// which Connect returns.
+type remoteBrowser struct {
+	ChannelOwner
+}
+
+func newRemoteBrowser(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *remoteBrowser {
+	bt := &remoteBrowser{}
+	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
+	return bt
 }
 
 func newBrowserType(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *BrowserType {
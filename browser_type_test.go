@@ -1,8 +1,17 @@
 package playwright
 
 import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,6 +27,122 @@ func TestBrowserTypeExecutablePath(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
 	require.Greater(t, len(helper.Playwright.Chromium.ExecutablePath()), 0)
+	require.FileExists(t, helper.BrowserType.ExecutablePath())
+
+	unknown := &BrowserType{}
+	unknown.initializer = map[string]interface{}{"name": "chromium"}
+	require.Equal(t, "", unknown.ExecutablePath())
+}
+
+func TestBrowserTypeLaunchExecutablePath(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		ExecutablePath: String(helper.BrowserType.ExecutablePath()),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, browser.Version())
+	require.NoError(t, browser.Close())
+}
+
+func TestBrowserTypeEnsureInstalledExecutablePath(t *testing.T) {
+	installs := 0
+	browserType := &BrowserType{
+		installBrowser: func(name string) error {
+			require.Equal(t, "chromium", name)
+			installs++
+			return nil
+		},
+	}
+	browserType.initializer = map[string]interface{}{"name": "chromium"}
+	require.NoError(t, browserType.ensureInstalled(String("/usr/bin/chromium")))
+	require.Equal(t, 0, installs)
+	require.NoError(t, browserType.ensureInstalled(nil))
+	require.NoError(t, browserType.ensureInstalled(nil))
+	require.Equal(t, 1, installs)
+}
+
+func TestBrowserTypeLaunchProxy(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	helper.server.SetRoute("/target.html", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") == "" {
+			w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		_, err := w.Write([]byte("<html><title>Served by the proxy</title></html>"))
+		require.NoError(t, err)
+	})
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		Proxy: &BrowserTypeLaunchProxy{
+			Server:   String(helper.server.PREFIX),
+			Username: String("user"),
+			Password: String("secret"),
+		},
+	})
+	require.NoError(t, err)
+	defer browser.Close()
+	page, err := browser.NewPage()
+	require.NoError(t, err)
+	authorization := helper.server.WaitForRequestChan("/target.html")
+	_, err = page.Goto("http://non-existent.com/target.html")
+	require.NoError(t, err)
+	title, err := page.Title()
+	require.NoError(t, err)
+	require.Equal(t, "Served by the proxy", title)
+	require.Equal(t, "http://non-existent.com/target.html", (<-authorization).RequestURI)
+}
+
+func TestBrowserTypeLaunchArgs(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		Args:              []string{"--disable-dev-shm-usage"},
+		IgnoreDefaultArgs: []string{"--mute-audio"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, browser.Close())
+}
+
+func TestCheckIgnoreDefaultArgs(t *testing.T) {
+	require.NoError(t, checkIgnoreDefaultArgs(nil))
+	require.NoError(t, checkIgnoreDefaultArgs(true))
+	require.NoError(t, checkIgnoreDefaultArgs([]string{"--mute-audio"}))
+	err := checkIgnoreDefaultArgs("--mute-audio")
+	require.EqualError(t, err, "IgnoreDefaultArgs must be a bool or a []string, got string")
+}
+
+func TestBrowserTypeLaunchDevtools(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	if !helper.IsChromium {
+		t.Skip("only Chromium supports the DevTools option")
+	}
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" {
+		t.Skip("the DevTools need a headed browser")
+	}
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		Devtools: Bool(true),
+	})
+	require.NoError(t, err)
+	page, err := browser.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	require.NoError(t, browser.Close())
+}
+
+func TestBrowserTypeLaunchTimeout(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	_, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		Timeout:       Int(1),
+		HandleSIGINT:  Bool(false),
+		HandleSIGTERM: Bool(false),
+		HandleSIGHUP:  Bool(false),
+	})
+	require.True(t, errors.Is(err, ErrTimeout))
 }
 
 func TestBrowserTypeLaunchPersistentContext(t *testing.T) {
@@ -57,3 +182,207 @@ func TestBrowserTypeLaunchPersistentContext(t *testing.T) {
 	require.NotEqual(t, "hello", result)
 	require.NoError(t, browser_context3.Close())
 }
+
+func TestBrowserTypeLaunchPersistentContextTracked(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	userDataDir := t.TempDir()
+	browserContext, err := helper.BrowserType.LaunchPersistentContext(userDataDir)
+	require.NoError(t, err)
+	page, err := browserContext.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	_, err = page.Evaluate("() => localStorage.hey = 'hello'")
+	require.NoError(t, err)
+	require.Equal(t, []*BrowserContext{browserContext}, helper.BrowserType.persistentContexts)
+	closed := make(chan bool, 1)
+	browserContext.Once("close", func() {
+		closed <- true
+	})
+	require.NoError(t, browserContext.Close())
+	<-closed
+	require.Len(t, helper.BrowserType.persistentContexts, 0)
+
+	browserContext, err = helper.BrowserType.LaunchPersistentContext(userDataDir)
+	require.NoError(t, err)
+	page, err = browserContext.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	result, err := page.Evaluate("() => localStorage.hey")
+	require.NoError(t, err)
+	require.Equal(t, "hello", result)
+	require.NoError(t, browserContext.Close())
+}
+
+func TestBrowserTypeConnect(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, message := range []string{
+			`{"guid":"","method":"__create__","params":{"type":"Browser","guid":"browser@1","initializer":{"version":"86.0"}}}`,
+			`{"guid":"","method":"__create__","params":{"type":"RemoteBrowser","guid":"remoteBrowser","initializer":{"browser":{"guid":"browser@1"}}}}`,
+		} {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+				return
+			}
+		}
+		for {
+			message := map[string]interface{}{}
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			received <- message
+			if err := conn.WriteJSON(map[string]interface{}{"id": message["id"], "result": map[string]interface{}{}}); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	chromium := &BrowserType{}
+	browser, err := chromium.Connect("ws"+strings.TrimPrefix(server.URL, "http"), BrowserTypeConnectOptions{
+		SlowMo: Int(10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "86.0", browser.Version())
+	require.True(t, browser.isRemote)
+	require.Equal(t, chromium, browser.browserType)
+	require.Equal(t, 10*time.Millisecond, browser.connection.slowMo)
+	require.NoError(t, browser.Close())
+	message := <-received
+	require.Equal(t, "browser@1", message["guid"])
+	require.Equal(t, "close", message["method"])
+}
+
+func TestBrowserTypeConnectWithoutBrowser(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+	_, err := (&BrowserType{}).Connect("ws" + strings.TrimPrefix(server.URL, "http"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "did not provide a browser")
+}
+
+func TestBrowserTypeConnectHeadersAndTLS(t *testing.T) {
+	handshake := make(chan *http.Request, 1)
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshake <- r
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+	pw := &Playwright{Chromium: &BrowserType{}}
+	_, err := pw.Chromium.Connect("wss"+strings.TrimPrefix(server.URL, "https"), BrowserTypeConnectOptions{
+		Headers: map[string]string{
+			"Authorization": "Bearer secret",
+		},
+		TLSConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+		EnableCompression: Bool(true),
+	})
+	require.Error(t, err)
+	request := <-handshake
+	require.Equal(t, "Bearer secret", request.Header.Get("Authorization"))
+	require.Contains(t, request.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+}
+
+func TestSerializeEnv(t *testing.T) {
+	require.Equal(t, []map[string]string{
+		{"name": "FOO", "value": "bar"},
+		{"name": "PORT", "value": "8080"},
+	}, serializeEnv(map[string]interface{}{
+		"PORT": 8080,
+		"FOO":  "bar",
+	}))
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "A", "value": "1"},
+	}, transformOptions([]BrowserTypeLaunchOptions{{Env: map[string]interface{}{"A": 2}}}, map[string]interface{}{
+		"env": serializeEnv(map[string]interface{}{"A": 1}),
+	})["env"])
+}
+
+func TestBrowserTypeHeadlessModeArgs(t *testing.T) {
+	chromium := &BrowserType{}
+	chromium.initializer = map[string]interface{}{"name": "chromium"}
+	chrome := String("/opt/google/chrome/chrome")
+	headless, args, err := chromium.headlessModeArgs(String("new"), nil, []string{"--foo"}, chrome)
+	require.NoError(t, err)
+	require.False(t, *headless)
+	require.Equal(t, []string{"--foo", "--headless=new"}, args)
+
+	headless, args, err = chromium.headlessModeArgs(String("old"), Bool(true), nil, nil)
+	require.NoError(t, err)
+	require.True(t, *headless)
+	require.Nil(t, args)
+
+	headless, _, err = chromium.headlessModeArgs(nil, Bool(false), nil, nil)
+	require.NoError(t, err)
+	require.False(t, *headless)
+
+	_, _, err = chromium.headlessModeArgs(String("new"), Bool(false), nil, chrome)
+	require.Error(t, err)
+	// the bundled Chromium would open a headed window
+	_, _, err = chromium.headlessModeArgs(String("new"), nil, nil, nil)
+	require.EqualError(t, err, "the new headless mode needs a Chrome 109 or newer of the Channel or ExecutablePath option, the bundled Chromium does not support it")
+	_, _, err = chromium.headlessModeArgs(String("shell"), nil, nil, nil)
+	require.EqualError(t, err, `unknown headless mode "shell", it is either "new" or "old"`)
+
+	firefox := &BrowserType{}
+	firefox.initializer = map[string]interface{}{"name": "firefox"}
+	_, _, err = firefox.headlessModeArgs(String("new"), nil, nil, chrome)
+	require.Error(t, err)
+}
+
+func TestBrowserTypeLaunchFirefoxUserPrefs(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	if !helper.IsFirefox {
+		t.Skip("only Firefox supports user preferences")
+	}
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		FirefoxUserPrefs: map[string]interface{}{
+			"general.useragent.override": "playwright-go",
+		},
+	})
+	require.NoError(t, err)
+	defer browser.Close()
+	page, err := browser.NewPage()
+	require.NoError(t, err)
+	userAgent, err := page.Evaluate("() => navigator.userAgent")
+	require.NoError(t, err)
+	require.Equal(t, "playwright-go", userAgent)
+}
+
+func TestBrowserTypeLaunchChromiumSandbox(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	if !helper.IsChromium {
+		t.Skip("only Chromium has the sandbox option")
+	}
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		ChromiumSandbox: Bool(false),
+	})
+	require.NoError(t, err)
+	defer browser.Close()
+	page, err := browser.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+}
@@ -0,0 +1,53 @@
+package playwright
+
+import "sync"
+
+// byteBudget limits the bytes of received messages which were not processed
+// yet. A nil budget is unlimited.
+type byteBudget struct {
+	sync.Mutex
+	cond  *sync.Cond
+	limit int
+	used  int
+}
+
+// acquire blocks until size bytes fit into the budget. A message bigger than
+// the whole budget is admitted once nothing else is in use.
+func (b *byteBudget) acquire(size int) {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	if size > b.limit {
+		size = b.limit
+	}
+	for b.used > 0 && b.used+size > b.limit {
+		b.cond.Wait()
+	}
+	b.used += size
+}
+
+func (b *byteBudget) release(size int) {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	if size > b.limit {
+		size = b.limit
+	}
+	b.used -= size
+	b.cond.Broadcast()
+}
+
+func newByteBudget(limit int) *byteBudget {
+	if limit <= 0 {
+		return nil
+	}
+	budget := &byteBudget{
+		limit: limit,
+	}
+	budget.cond = sync.NewCond(budget)
+	return budget
+}
@@ -0,0 +1,56 @@
+package playwright
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteBudget(t *testing.T) {
+	budget := newByteBudget(100)
+	budget.acquire(60)
+	acquired := make(chan struct{})
+	go func() {
+		budget.acquire(60)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("budget was exceeded")
+	case <-time.After(50 * time.Millisecond):
+	}
+	budget.release(60)
+	<-acquired
+	budget.release(60)
+
+	// a message bigger than the budget passes when nothing else is in use
+	budget.acquire(1000)
+	budget.release(1000)
+}
+
+func TestByteBudgetUnlimited(t *testing.T) {
+	budget := newByteBudget(0)
+	require.Nil(t, budget)
+	budget.acquire(1 << 30)
+	budget.release(1 << 30)
+}
+
+func TestConnectionReleasesBudget(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.budget = newByteBudget(100)
+	go func() {
+		message := <-fake.sent
+		fake.received <- &Message{ID: message["id"].(int), size: 80}
+	}()
+	go func() {
+		_ = connection.Start()
+	}()
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.NoError(t, err)
+	connection.budget.Lock()
+	used := connection.budget.used
+	connection.budget.Unlock()
+	require.Equal(t, 0, used)
+	close(fake.received)
+}
@@ -0,0 +1,46 @@
+package playwright
+
+import "fmt"
+
+// CDPSession talks the raw Chrome DevTools Protocol with Chromium. The CDP
+// events are emitted with their method as the name and their params as the
+// payload, e.g.
+//
+//	session.On("Target.targetCreated", func(params map[string]interface{}) {})
+type CDPSession struct {
+	ChannelOwner
+}
+
+// Send calls the CDP method, e.g. "SystemInfo.getInfo", and returns its result.
+func (c *CDPSession) Send(method string, params map[string]interface{}) (interface{}, error) {
+	result, err := c.channel.Send("send", map[string]interface{}{
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not send CDP message %s: %w", method, err)
+	}
+	return result, nil
+}
+
+// OnEvent calls the handler with the params of every event of the CDP method,
+// e.g. "Network.requestWillBeSent". The events of a domain need to be enabled
+// first, e.g. with Send("Network.enable", nil).
+func (c *CDPSession) OnEvent(method string, handler func(params map[string]interface{})) {
+	c.On(method, handler)
+}
+
+// Detach detaches the session, it can not be used afterwards.
+func (c *CDPSession) Detach() error {
+	_, err := c.channel.Send("detach")
+	return err
+}
+
+func newCDPSession(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *CDPSession {
+	bt := &CDPSession{}
+	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
+	bt.channel.On("event", func(ev map[string]interface{}) {
+		bt.Emit(ev["method"].(string), ev["params"])
+	})
+	return bt
+}
@@ -1,6 +1,8 @@
 package playwright
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -11,13 +13,33 @@ type Channel struct {
 	guid       string
 	connection *Connection
 	object     interface{}
+	owner      *ChannelOwner
+}
+
+// addCloseReason adds the reason of Browser.Close to the error of a call
+// which failed because the browser was closed.
+func (c *Channel) addCloseReason(err error) error {
+	var targetClosed *TargetClosedError
+	if !errors.As(err, &targetClosed) {
+		return err
+	}
+	if reason := browserCloseReason(c.owner); reason != "" {
+		targetClosed.Message += ": " + reason
+	}
+	return err
 }
 
 func (c *Channel) Send(method string, options ...interface{}) (interface{}, error) {
+	return c.SendContext(context.Background(), method, options...)
+}
+
+// SendContext is like Send but returns ctx.Err() once ctx is done before the
+// server replied.
+func (c *Channel) SendContext(ctx context.Context, method string, options ...interface{}) (interface{}, error) {
 	params := transformOptions(options...)
-	result, err := c.connection.SendMessageToServer(c.guid, method, params)
+	result, err := c.connection.SendMessageToServerContext(ctx, c.guid, method, params)
 	if err != nil {
-		return nil, fmt.Errorf("could not send message to server: %w", err)
+		return nil, fmt.Errorf("could not send message to server: %w", c.addCloseReason(err))
 	}
 	if result == nil {
 		return nil, nil
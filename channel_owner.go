@@ -33,6 +33,7 @@ func (c *ChannelOwner) createChannelOwner(self interface{}, parent *ChannelOwner
 	c.connection = parent.connection
 	c.channel = newChannel(c.connection, guid)
 	c.channel.object = self
+	c.channel.owner = c
 	c.initializer = initializer
 	c.connection.objects[guid] = c
 	c.parent.objects[guid] = c
@@ -48,6 +49,7 @@ func newRootChannelOwner(connection *Connection) *ChannelOwner {
 		channel:    newChannel(connection, ""),
 	}
 	c.channel.object = c
+	c.channel.owner = c
 	c.connection.objects[""] = c
 	c.initEventEmitter()
 	return c
@@ -0,0 +1,93 @@
+// Command playwright manages the Playwright driver and browsers which are used
+// by playwright-go. It can be used to pre-warm caches on CI machines:
+//
+//	go run github.com/mxschmitt/playwright-go/cmd/playwright install chromium
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mxschmitt/playwright-go"
+)
+
+const usage = `Usage: playwright [flags] <command> [arguments]
+
+Commands:
+  install [--with-deps] [browser...]
+                        download the driver and the given browsers (default: all)
+  uninstall             remove the driver and the downloaded browsers
+  verify [browser...]   report what is installed and what install would download
+  version               print the driver version and the installed one
+
+Flags:
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	driverDirectory := flag.String("driver-dir", "", "folder where the driver is stored (default: user cache directory)")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	options := &playwright.InstallOptions{
+		DriverDirectory: *driverDirectory,
+	}
+	switch flag.Arg(0) {
+	case "install":
+		installFlags := flag.NewFlagSet("install", flag.ExitOnError)
+		withDeps := installFlags.Bool("with-deps", false, "install the system dependencies of the browsers as well")
+		if err := installFlags.Parse(flag.Args()[1:]); err != nil {
+			log.Fatalf("could not parse flags: %v", err)
+		}
+		options.Browsers = installFlags.Args()
+		options.WithDeps = *withDeps
+		if err := playwright.Install(options); err != nil {
+			log.Fatalf("could not install: %v", err)
+		}
+	case "uninstall":
+		if err := playwright.Uninstall(options); err != nil {
+			log.Fatalf("could not uninstall: %v", err)
+		}
+	case "version":
+		driverPath, err := playwright.InstalledDriverPath(options)
+		if err != nil {
+			log.Fatalf("could not get driver path: %v", err)
+		}
+		fmt.Printf("Driver version: %s\n", playwright.DriverVersion())
+		if driverPath == "" {
+			fmt.Println("Driver location: not installed")
+			return
+		}
+		fmt.Printf("Driver location: %s\n", driverPath)
+		installedVersion, err := playwright.InstalledDriverVersion(options)
+		if err != nil {
+			log.Fatalf("could not get installed driver version: %v", err)
+		}
+		fmt.Printf("Installed driver version: %s\n", installedVersion)
+	case "verify":
+		options.Browsers = flag.Args()[1:]
+		report, err := playwright.Verify(options)
+		if err != nil {
+			log.Fatalf("could not verify: %v", err)
+		}
+		fmt.Printf("Driver: %s (installed: %t, version: %q, up to date: %t)\n", report.DriverPath, report.DriverInstalled, report.DriverVersion, report.DriverUpToDate)
+		fmt.Printf("Browsers: %s\n", report.BrowsersPath)
+		for _, browser := range report.Browsers {
+			fmt.Printf("  %s: %v\n", browser.Name, browser.Revisions)
+		}
+		fmt.Printf("Install would download: %v\n", report.WouldDownload)
+	case "help":
+		flag.Usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -1,64 +1,329 @@
 package playwright
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/square/go-jose.v2/json"
 )
 
 type callback struct {
 	Data  interface{}
 	Error error
+	// size of the message which carried the result, see byteBudget
+	size int
+}
+
+// pendingCall waits for the result of a call. Whoever claims it first, the
+// dispatcher with a result or the caller giving up, is responsible for it.
+type pendingCall struct {
+	result  chan callback
+	claimed int32
+}
+
+func (p *pendingCall) claim() bool {
+	return atomic.CompareAndSwapInt32(&p.claimed, 0, 1)
 }
 
+// Connection correlates the calls with their results by their ID only, so
+// calls from different goroutines, e.g. on different pages, do not wait for
+// each other. None of the locks is held while waiting for the driver.
 type Connection struct {
-	transport                   *Transport
+	// The counters are accessed atomically and come first to be 64-bit
+	// aligned on 32-bit platforms.
+	lastID                      int64
+	inFlightCalls               int64
+	messagesSent                int64
+	messagesReceived            int64
+	totalRoundTrip              int64
+	roundTrips                  int64
+	transport                   Transport
 	waitingForRemoteObjectsLock sync.Mutex
 	waitingForRemoteObjects     map[string]chan interface{}
 	objects                     map[string]*ChannelOwner
-	lastID                      int
-	lastIDLock                  sync.Mutex
 	rootObject                  *ChannelOwner
 	callbacks                   sync.Map
 	stopDriver                  func() error
+	onMessage                   func(direction, method string, payload []byte)
+	onMessageLock               sync.RWMutex
+	timeoutSettings             *timeoutSettings
+	closeLock                   sync.RWMutex
+	closed                      chan struct{}
+	closeErr                    error
+	stopping                    bool
+	lastErrorLock               sync.Mutex
+	lastError                   error
+	budget                      *byteBudget
+	closeHandlers               []*closeHandler
+	// slowMo delays the calls of a connected browser, see
+	// BrowserTypeConnectOptions.SlowMo
+	slowMo time.Duration
+}
+
+// ConnectionMetrics is a snapshot of the health of a connection to the driver.
+type ConnectionMetrics struct {
+	// InFlightCalls is the number of calls which wait for their result.
+	InFlightCalls int
+	// MessagesSent is the number of messages which were sent to the driver.
+	MessagesSent int64
+	// MessagesReceived is the number of messages which were received from the
+	// driver, including events.
+	MessagesReceived int64
+	// AverageRoundTrip is the average time between sending a call and
+	// receiving its result.
+	AverageRoundTrip time.Duration
+	// LastError is the last error which a call returned, if any.
+	LastError error
+}
+
+// Metrics returns a snapshot of the counters of the connection, e.g. to
+// export them as health metrics.
+func (c *Connection) Metrics() ConnectionMetrics {
+	metrics := ConnectionMetrics{
+		InFlightCalls:    int(atomic.LoadInt64(&c.inFlightCalls)),
+		MessagesSent:     atomic.LoadInt64(&c.messagesSent),
+		MessagesReceived: atomic.LoadInt64(&c.messagesReceived),
+	}
+	if roundTrips := atomic.LoadInt64(&c.roundTrips); roundTrips > 0 {
+		metrics.AverageRoundTrip = time.Duration(atomic.LoadInt64(&c.totalRoundTrip) / roundTrips)
+	}
+	c.lastErrorLock.Lock()
+	metrics.LastError = c.lastError
+	c.lastErrorLock.Unlock()
+	return metrics
+}
+
+func (c *Connection) recordCallStart() {
+	atomic.AddInt64(&c.inFlightCalls, 1)
+	atomic.AddInt64(&c.messagesSent, 1)
+}
+
+// recordCallEnd records a finished call, roundTrip is zero if the call did not
+// get a result from the driver.
+func (c *Connection) recordCallEnd(roundTrip time.Duration, err error) {
+	atomic.AddInt64(&c.inFlightCalls, -1)
+	if roundTrip > 0 {
+		atomic.AddInt64(&c.totalRoundTrip, int64(roundTrip))
+		atomic.AddInt64(&c.roundTrips, 1)
+	}
+	if err != nil {
+		c.lastErrorLock.Lock()
+		c.lastError = err
+		c.lastErrorLock.Unlock()
+	}
 }
 
+// Directions of the protocol messages which are passed to the OnMessage hook.
+const (
+	MessageDirectionSend    = "send"
+	MessageDirectionReceive = "recv"
+)
+
+// OnMessage registers a hook which gets every raw protocol message which is
+// exchanged with the driver, e.g. to dump the traffic while diagnosing hangs.
+// Responses have an empty method. Passing nil removes the hook.
+func (c *Connection) OnMessage(hook func(direction, method string, payload []byte)) {
+	c.onMessageLock.Lock()
+	defer c.onMessageLock.Unlock()
+	c.onMessage = hook
+}
+
+func (c *Connection) emitMessage(direction, method string, message interface{}) {
+	c.onMessageLock.RLock()
+	hook := c.onMessage
+	c.onMessageLock.RUnlock()
+	if hook == nil {
+		return
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("could not marshal message for the message hook: %v", err)
+		return
+	}
+	hook(direction, method, payload)
+}
+
+// Start dispatches the incoming messages until the transport is closed.
 func (c *Connection) Start() error {
-	return c.transport.Start()
+	for {
+		msg, err := c.transport.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		// Stop reading while too many bytes wait for being processed, so
+		// that the driver has to wait as well.
+		c.budget.acquire(msg.size)
+		c.Dispatch(msg)
+	}
+}
+
+// transportClosed fails all pending and future calls. It has to be called once
+// the transport stopped reading, err is the error which Start returned.
+func (c *Connection) transportClosed(err error) {
+	c.closeLock.Lock()
+	defer c.closeLock.Unlock()
+	switch {
+	case c.stopping:
+		c.closeWithError(errConnectionClosed)
+	case err != nil:
+		c.closeWithError(fmt.Errorf("%w: %v", ErrDriverCrashed, err))
+	default:
+		c.closeWithError(ErrDriverCrashed)
+	}
+}
+
+// heartbeat pings the driver every interval and closes the connection with
+// ErrDriverUnresponsive if it does not reply in time.
+func (c *Connection) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		// There is no ping in the protocol, but the root object replies to
+		// every call, with an error for unknown methods.
+		_, err := c.sendMessageToServer(ctx, "", "ping", nil, true)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.closeLock.Lock()
+			c.closeWithError(fmt.Errorf("%w: no reply within %s", ErrDriverUnresponsive, interval))
+			c.closeLock.Unlock()
+			go func() {
+				_ = c.Stop()
+			}()
+			return
+		}
+	}
+}
+
+// closeWithError fails all pending and future calls with err. closeLock has to
+// be held.
+func (c *Connection) closeWithError(err error) {
+	if c.closeErr != nil {
+		return
+	}
+	c.closeErr = err
+	close(c.closed)
+	for _, handler := range c.closeHandlers {
+		go handler.handle(c.closeError())
+	}
+	c.callbacks.Range(func(id, call interface{}) bool {
+		c.callbacks.Delete(id)
+		if call.(*pendingCall).claim() {
+			call.(*pendingCall).result <- callback{Error: c.closeErr}
+		}
+		return true
+	})
+}
+
+// OnClose registers a handler which is called once the connection terminated.
+// err is nil if it was stopped by Stop, otherwise it explains why it
+// terminated, e.g. ErrDriverCrashed. Handlers which are registered after the
+// termination are called right away.
+func (c *Connection) OnClose(handler func(err error)) {
+	c.addCloseHandler(&closeHandler{handle: handler})
+}
+
+// closeHandler is a handler of OnClose, which can be removed again by
+// removeCloseHandler.
+type closeHandler struct {
+	handle func(err error)
+}
+
+func (c *Connection) addCloseHandler(handler *closeHandler) {
+	c.closeLock.Lock()
+	defer c.closeLock.Unlock()
+	if c.closeErr != nil {
+		go handler.handle(c.closeError())
+		return
+	}
+	c.closeHandlers = append(c.closeHandlers, handler)
+}
+
+func (c *Connection) removeCloseHandler(handler *closeHandler) {
+	c.closeLock.Lock()
+	defer c.closeLock.Unlock()
+	for i, h := range c.closeHandlers {
+		if h == handler {
+			c.closeHandlers = append(c.closeHandlers[:i], c.closeHandlers[i+1:]...)
+			return
+		}
+	}
+}
+
+// closeError returns the error for the close handlers. closeLock has to be
+// held.
+func (c *Connection) closeError() error {
+	if c.closeErr == errConnectionClosed {
+		return nil
+	}
+	return c.closeErr
 }
 
 func (c *Connection) Stop() error {
-	if err := c.transport.Stop(); err != nil {
-		return fmt.Errorf("could not stop transport: %w", err)
+	c.closeLock.Lock()
+	c.stopping = true
+	c.closeLock.Unlock()
+	if err := c.transport.Close(); err != nil {
+		return fmt.Errorf("could not close transport: %w", err)
 	}
 	return c.stopDriver()
 }
 
 func (c *Connection) CallOnObjectWithKnownName(name string) (interface{}, error) {
+	c.waitingForRemoteObjectsLock.Lock()
 	if _, ok := c.waitingForRemoteObjects[name]; !ok {
-		c.waitingForRemoteObjectsLock.Lock()
 		c.waitingForRemoteObjects[name] = make(chan interface{})
-		c.waitingForRemoteObjectsLock.Unlock()
 	}
-	return <-c.waitingForRemoteObjects[name], nil
+	waiting := c.waitingForRemoteObjects[name]
+	c.waitingForRemoteObjectsLock.Unlock()
+	select {
+	case object := <-waiting:
+		return object, nil
+	case <-c.closed:
+		return nil, c.closeErr
+	}
 }
 
 func (c *Connection) Dispatch(msg *Message) {
+	atomic.AddInt64(&c.messagesReceived, 1)
+	c.emitMessage(MessageDirectionReceive, msg.Method, msg)
 	method := msg.Method
 	if msg.ID != 0 {
-		cb, _ := c.callbacks.Load(msg.ID)
+		call, ok := c.callbacks.Load(msg.ID)
+		if !ok || !call.(*pendingCall).claim() {
+			// the caller is not waiting anymore, e.g. its context was cancelled
+			c.budget.release(msg.size)
+			return
+		}
+		c.callbacks.Delete(msg.ID)
 		if msg.Error != nil {
-			cb.(chan callback) <- callback{
+			call.(*pendingCall).result <- callback{
 				Error: parseError(msg.Error.Error),
+				size:  msg.size,
 			}
 		} else {
-			cb.(chan callback) <- callback{
+			call.(*pendingCall).result <- callback{
 				Data: c.replaceGuidsWithChannels(msg.Result),
+				size: msg.size,
 			}
 		}
 		return
 	}
+	defer c.budget.release(msg.size)
 	object := c.objects[msg.GUID]
 	if method == "__create__" {
 		c.createRemoteObject(
@@ -140,35 +405,92 @@ func (c *Connection) replaceGuidsWithChannels(payload interface{}) interface{} {
 }
 
 func (c *Connection) SendMessageToServer(guid string, method string, params interface{}) (interface{}, error) {
-	c.lastIDLock.Lock()
-	c.lastID++
-	id := c.lastID
-	c.lastIDLock.Unlock()
+	return c.SendMessageToServerContext(context.Background(), guid, method, params)
+}
+
+// SendMessageToServerContext is like SendMessageToServer but stops waiting
+// for the result once ctx is done. The server is not notified about it.
+func (c *Connection) SendMessageToServerContext(ctx context.Context, guid string, method string, params interface{}) (interface{}, error) {
+	return c.sendMessageToServer(ctx, guid, method, params, false)
+}
+
+// sendMessageToServer sends the call and waits for its result. The pings of
+// the heartbeat are not slowed down and left out of the metrics, as their
+// replies are errors.
+func (c *Connection) sendMessageToServer(ctx context.Context, guid string, method string, params interface{}, heartbeat bool) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.slowMo > 0 && !heartbeat {
+		select {
+		case <-time.After(c.slowMo):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	id := int(atomic.AddInt64(&c.lastID, 1))
 	message := map[string]interface{}{
 		"id":     id,
 		"guid":   guid,
 		"method": method,
 		"params": c.replaceChannelsWithGuids(params),
 	}
-	cb, _ := c.callbacks.LoadOrStore(id, make(chan callback))
-	if err := c.transport.Send(message); err != nil {
-		return nil, fmt.Errorf("could not send message: %w", err)
+	c.emitMessage(MessageDirectionSend, method, message)
+	call := &pendingCall{
+		result: make(chan callback, 1),
+	}
+	c.closeLock.RLock()
+	if c.closeErr != nil {
+		c.closeLock.RUnlock()
+		return nil, c.closeErr
+	}
+	c.callbacks.Store(id, call)
+	c.closeLock.RUnlock()
+	if !heartbeat {
+		c.recordCallStart()
+	}
+	sentAt := time.Now()
+	if err := c.transport.Write(message); err != nil {
+		c.callbacks.Delete(id)
+		err = fmt.Errorf("could not send message: %w", err)
+		if !heartbeat {
+			c.recordCallEnd(0, err)
+		}
+		return nil, err
+	}
+	var result callback
+	select {
+	case result = <-call.result:
+	case <-ctx.Done():
+		if call.claim() {
+			c.callbacks.Delete(id)
+			if !heartbeat {
+				c.recordCallEnd(0, ctx.Err())
+			}
+			return nil, ctx.Err()
+		}
+		// the result arrived concurrently
+		result = <-call.result
+	}
+	c.budget.release(result.size)
+	if !heartbeat {
+		c.recordCallEnd(time.Since(sentAt), result.Error)
 	}
-	result := <-cb.(chan callback)
-	c.callbacks.Delete(id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return result.Data, nil
 }
 
-func newConnection(stdin io.WriteCloser, stdout io.ReadCloser, stopDriver func() error) *Connection {
+func newConnection(transport Transport, stopDriver func() error) *Connection {
 	connection := &Connection{
 		waitingForRemoteObjects: make(map[string]chan interface{}),
 		objects:                 make(map[string]*ChannelOwner),
 		stopDriver:              stopDriver,
+		timeoutSettings:         newTimeoutSettings(nil),
+		closed:                  make(chan struct{}),
 	}
-	connection.transport = newTransport(stdin, stdout, connection.Dispatch)
+	connection.transport = transport
 	connection.rootObject = newRootChannelOwner(connection)
 	return connection
 }
@@ -0,0 +1,307 @@
+package playwright
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport hands the sent messages to the test instead of a driver.
+type fakeTransport struct {
+	sent     chan map[string]interface{}
+	received chan *Message
+}
+
+func (t *fakeTransport) Read() (*Message, error) {
+	msg, ok := <-t.received
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (t *fakeTransport) Close() error {
+	return nil
+}
+
+func (t *fakeTransport) Write(message map[string]interface{}) error {
+	t.sent <- message
+	return nil
+}
+
+func newFakeConnection() (*Connection, *fakeTransport) {
+	fake := &fakeTransport{
+		sent:     make(chan map[string]interface{}, 10),
+		received: make(chan *Message, 10),
+	}
+	connection := newConnection(fake, func() error {
+		return nil
+	})
+	return connection, fake
+}
+
+func TestConnectionSendMessageToServer(t *testing.T) {
+	connection, fake := newFakeConnection()
+	go func() {
+		message := <-fake.sent
+		connection.Dispatch(&Message{
+			ID:     message["id"].(int),
+			Result: map[string]interface{}{"value": "bar"},
+		})
+	}()
+	result, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": "bar"}, result)
+}
+
+func TestConnectionSlowMo(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.slowMo = 50 * time.Millisecond
+	go func() {
+		message := <-fake.sent
+		connection.Dispatch(&Message{ID: message["id"].(int)})
+	}()
+	start := time.Now()
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+}
+
+func TestConnectionSendMessageToServerContextCancelled(t *testing.T) {
+	connection, fake := newFakeConnection()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := connection.SendMessageToServerContext(ctx, "foo", "bar", nil)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// a late reply must not block the dispatcher
+	message := <-fake.sent
+	dispatched := make(chan struct{})
+	go func() {
+		connection.Dispatch(&Message{ID: message["id"].(int)})
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch of a late reply blocked")
+	}
+}
+
+func TestConnectionOnMessage(t *testing.T) {
+	connection, fake := newFakeConnection()
+	type loggedMessage struct {
+		direction string
+		method    string
+		payload   string
+	}
+	logged := make(chan loggedMessage, 2)
+	connection.OnMessage(func(direction, method string, payload []byte) {
+		logged <- loggedMessage{direction, method, string(payload)}
+	})
+	go func() {
+		message := <-fake.sent
+		connection.Dispatch(&Message{ID: message["id"].(int)})
+	}()
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.NoError(t, err)
+	sent := <-logged
+	require.Equal(t, MessageDirectionSend, sent.direction)
+	require.Equal(t, "bar", sent.method)
+	require.Contains(t, sent.payload, `"guid":"foo"`)
+	received := <-logged
+	require.Equal(t, MessageDirectionReceive, received.direction)
+	require.Equal(t, "", received.method)
+
+	connection.OnMessage(nil)
+	go func() {
+		message := <-fake.sent
+		connection.Dispatch(&Message{ID: message["id"].(int)})
+	}()
+	_, err = connection.SendMessageToServer("foo", "bar", nil)
+	require.NoError(t, err)
+	require.Len(t, logged, 0)
+}
+
+func TestConnectionDriverCrashed(t *testing.T) {
+	connection, fake := newFakeConnection()
+	result := make(chan error, 1)
+	go func() {
+		_, err := connection.SendMessageToServer("foo", "bar", nil)
+		result <- err
+	}()
+	<-fake.sent
+	connection.transportClosed(errors.New("unexpected EOF"))
+	require.True(t, errors.Is(<-result, ErrDriverCrashed))
+
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.True(t, errors.Is(err, ErrDriverCrashed))
+	_, err = connection.CallOnObjectWithKnownName("Playwright")
+	require.True(t, errors.Is(err, ErrDriverCrashed))
+}
+
+func TestConnectionStopIsNoCrash(t *testing.T) {
+	connection, _ := newFakeConnection()
+	require.NoError(t, connection.Stop())
+	connection.transportClosed(nil)
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrDriverCrashed))
+}
+
+func TestConnectionMetrics(t *testing.T) {
+	connection, fake := newFakeConnection()
+	require.Equal(t, ConnectionMetrics{}, connection.Metrics())
+	inFlightCalls := make(chan int, 1)
+	go func() {
+		message := <-fake.sent
+		inFlightCalls <- connection.Metrics().InFlightCalls
+		time.Sleep(time.Millisecond)
+		connection.Dispatch(&Message{
+			ID: message["id"].(int),
+			Error: &struct {
+				Error errorPayload `json:"error"`
+			}{Error: errorPayload{Message: "boom"}},
+		})
+	}()
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.Error(t, err)
+	require.Equal(t, 1, <-inFlightCalls)
+	metrics := connection.Metrics()
+	require.Equal(t, 0, metrics.InFlightCalls)
+	require.Equal(t, int64(1), metrics.MessagesSent)
+	require.Equal(t, int64(1), metrics.MessagesReceived)
+	require.GreaterOrEqual(t, int64(metrics.AverageRoundTrip), int64(time.Millisecond))
+	require.Equal(t, err, metrics.LastError)
+}
+
+func TestRunWithTransport(t *testing.T) {
+	fake := &fakeTransport{
+		sent:     make(chan map[string]interface{}, 10),
+		received: make(chan *Message, 10),
+	}
+	for _, name := range []string{"chromium", "firefox", "webkit"} {
+		fake.received <- &Message{
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type": "BrowserType",
+				"guid": "BrowserType@" + name,
+				"initializer": map[string]interface{}{
+					"name":           name,
+					"executablePath": "/bin/" + name,
+				},
+			},
+		}
+	}
+	fake.received <- &Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type": "Playwright",
+			"guid": "Playwright",
+			"initializer": map[string]interface{}{
+				"chromium":          map[string]interface{}{"guid": "BrowserType@chromium"},
+				"firefox":           map[string]interface{}{"guid": "BrowserType@firefox"},
+				"webkit":            map[string]interface{}{"guid": "BrowserType@webkit"},
+				"deviceDescriptors": []interface{}{},
+			},
+		},
+	}
+	pw, err := RunWithTransport(fake)
+	require.NoError(t, err)
+	require.Equal(t, "chromium", pw.Chromium.Name())
+	require.Equal(t, "/bin/webkit", pw.WebKit.ExecutablePath())
+	close(fake.received)
+	require.NoError(t, pw.Stop())
+}
+
+func TestConnectionHeartbeat(t *testing.T) {
+	connection, fake := newFakeConnection()
+	go connection.heartbeat(10 * time.Millisecond)
+	ping := <-fake.sent
+	require.Equal(t, "ping", ping["method"])
+	connection.Dispatch(&Message{ID: ping["id"].(int)})
+
+	_, err := connection.SendMessageToServer("foo", "bar", nil)
+	require.True(t, errors.Is(err, ErrDriverUnresponsive))
+}
+
+func TestConnectionHeartbeatIsNotMetered(t *testing.T) {
+	connection, fake := newFakeConnection()
+	go connection.heartbeat(time.Second)
+	ping := <-fake.sent
+	connection.Dispatch(&Message{
+		ID: ping["id"].(int),
+		Error: &struct {
+			Error errorPayload `json:"error"`
+		}{Error: errorPayload{Message: "Unknown method ping"}},
+	})
+	require.NoError(t, connection.Stop())
+	metrics := connection.Metrics()
+	require.Equal(t, int64(0), metrics.MessagesSent)
+	require.Equal(t, 0, metrics.InFlightCalls)
+	require.NoError(t, metrics.LastError)
+}
+
+func TestConnectionConcurrentCalls(t *testing.T) {
+	connection, fake := newFakeConnection()
+	const calls = 5
+	type result struct {
+		value interface{}
+		err   error
+	}
+	results := make(chan result, calls)
+	for i := 0; i < calls; i++ {
+		go func(i int) {
+			value, err := connection.SendMessageToServer(fmt.Sprintf("Page@%d", i), "title", nil)
+			results <- result{value, err}
+		}(i)
+	}
+	// all calls are sent before any of them got its result
+	messages := make([]map[string]interface{}, 0, calls)
+	for i := 0; i < calls; i++ {
+		messages = append(messages, <-fake.sent)
+	}
+	require.Equal(t, calls, connection.Metrics().InFlightCalls)
+	for i := len(messages) - 1; i >= 0; i-- {
+		connection.Dispatch(&Message{
+			ID:     messages[i]["id"].(int),
+			Result: messages[i]["guid"],
+		})
+	}
+	guids := make([]interface{}, 0, calls)
+	for i := 0; i < calls; i++ {
+		result := <-results
+		require.NoError(t, result.err)
+		guids = append(guids, result.value)
+	}
+	for _, message := range messages {
+		require.Contains(t, guids, message["guid"])
+	}
+}
+
+func TestConnectionOnClose(t *testing.T) {
+	connection, _ := newFakeConnection()
+	closed := make(chan error, 2)
+	connection.OnClose(func(err error) {
+		closed <- err
+	})
+	connection.transportClosed(errors.New("unexpected EOF"))
+	require.True(t, errors.Is(<-closed, ErrDriverCrashed))
+	connection.OnClose(func(err error) {
+		closed <- err
+	})
+	require.True(t, errors.Is(<-closed, ErrDriverCrashed))
+
+	connection, _ = newFakeConnection()
+	connection.OnClose(func(err error) {
+		closed <- err
+	})
+	require.NoError(t, connection.Stop())
+	connection.transportClosed(nil)
+	require.NoError(t, <-closed)
+}
@@ -18,3 +18,11 @@ func TestDialog(t *testing.T) {
 	_, err := helper.Page.Evaluate("alert('yo')")
 	require.NoError(t, err)
 }
+
+func TestDialogDismissedWithoutHandler(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	result, err := helper.Page.Evaluate("() => confirm('yo')")
+	require.NoError(t, err)
+	require.Equal(t, false, result)
+}
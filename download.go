@@ -34,9 +34,15 @@ func (d *Download) Failure() error {
 	return errors.New(path.(string))
 }
 
+// Path returns the path of the downloaded file, which is in the
+// DownloadsPath of the browser if it was launched with one. It waits for the
+// download to finish and fails if the context does not accept downloads.
 func (d *Download) Path() (string, error) {
 	path, err := d.channel.Send("path")
-	return path.(string), err
+	if err != nil {
+		return "", err
+	}
+	return path.(string), nil
 }
 
 func (d *Download) SaveAs(path string) error {
@@ -2,6 +2,7 @@ package playwright
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"path/filepath"
@@ -45,3 +46,62 @@ func TestDownloadBasic(t *testing.T) {
 	require.NoError(t, download.Delete())
 	require.NoFileExists(t, file)
 }
+
+func TestDownloadDownloadsPath(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	helper.server.SetRoute("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/octet-stream")
+		w.Header().Add("Content-Disposition", "attachment")
+		if _, err := w.Write([]byte("foobar")); err != nil {
+			log.Printf("could not write: %v", err)
+		}
+	})
+	downloadsPath := t.TempDir()
+	browser, err := helper.BrowserType.Launch(BrowserTypeLaunchOptions{
+		DownloadsPath: String(downloadsPath),
+	})
+	require.NoError(t, err)
+	defer browser.Close()
+	page, err := browser.NewPage(BrowserNewContextOptions{
+		AcceptDownloads: Bool(true),
+	})
+	require.NoError(t, err)
+	require.NoError(t, page.SetContent(
+		fmt.Sprintf(`<a href="%s/download">download</a>`, helper.server.PREFIX),
+	))
+	download, err := page.ExpectDownload(func() error {
+		return page.Click("a")
+	})
+	require.NoError(t, err)
+	file, err := download.Path()
+	require.NoError(t, err)
+	require.Equal(t, downloadsPath, filepath.Dir(file))
+	content, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", string(content))
+}
+
+func TestDownloadNotAccepted(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	helper.server.SetRoute("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/octet-stream")
+		w.Header().Add("Content-Disposition", "attachment")
+		if _, err := w.Write([]byte("foobar")); err != nil {
+			log.Printf("could not write: %v", err)
+		}
+	})
+	page, err := helper.Browser.NewPage()
+	require.NoError(t, err)
+	defer page.Context().Close()
+	require.NoError(t, page.SetContent(
+		fmt.Sprintf(`<a href="%s/download">download</a>`, helper.server.PREFIX),
+	))
+	download, err := page.ExpectDownload(func() error {
+		return page.Click("a")
+	})
+	require.NoError(t, err)
+	_, err = download.Path()
+	require.Error(t, err)
+}
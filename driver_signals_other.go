@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package playwright
+
+import "os/exec"
+
+// isolateSignals is a no-op on platforms without process groups.
+func isolateSignals(cmd *exec.Cmd) {}
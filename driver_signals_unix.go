@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package playwright
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateSignals starts the command in its own process group, so that the
+// signals to the group of the application do not reach it.
+func isolateSignals(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
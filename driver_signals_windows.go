@@ -0,0 +1,15 @@
+package playwright
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateSignals starts the command in a new process group, so that it does
+// not receive the CTRL+C of the console of the application.
+func isolateSignals(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
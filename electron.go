@@ -0,0 +1,160 @@
+package playwright
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Electron launches Electron applications, see Playwright.Electron.
+type Electron struct {
+	ChannelOwner
+}
+
+// ElectronLaunchOptions are the options of Electron.Launch.
+type ElectronLaunchOptions struct {
+	// Args are passed to the application, e.g. the path of its main script.
+	Args []string `json:"args"`
+	// Cwd is the working directory of the application.
+	Cwd *string `json:"cwd"`
+	// Env of the application, defaults to the one of the driver.
+	Env           map[string]interface{} `json:"-"`
+	HandleSIGINT  *bool                  `json:"handleSIGINT"`
+	HandleSIGTERM *bool                  `json:"handleSIGTERM"`
+	HandleSIGHUP  *bool                  `json:"handleSIGHUP"`
+	// Timeout in milliseconds to wait for the application to start.
+	Timeout *int `json:"timeout"`
+}
+
+// Launch starts the Electron application of the executablePath, e.g.
+// node_modules/.bin/electron with the main script in Args.
+func (e *Electron) Launch(executablePath string, options ...ElectronLaunchOptions) (*ElectronApplication, error) {
+	overrides := map[string]interface{}{
+		"executablePath": executablePath,
+	}
+	if len(options) == 1 && options[0].Env != nil {
+		overrides["env"] = serializeEnv(options[0].Env)
+	}
+	channel, err := e.channel.Send("launch", options, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("could not send message: %w", err)
+	}
+	return fromChannel(channel).(*ElectronApplication), nil
+}
+
+func newElectron(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *Electron {
+	bt := &Electron{}
+	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
+	return bt
+}
+
+// ElectronApplication is a launched Electron application. Its windows are
+// Pages, it emits "window" with the Page of each new window and "close".
+type ElectronApplication struct {
+	ChannelOwner
+	// windowsLock guards the context and the windows
+	windowsLock sync.Mutex
+	context     *BrowserContext
+	windows     []*Page
+}
+
+// Context returns the browser context of the windows of the application.
+func (e *ElectronApplication) Context() *BrowserContext {
+	e.windowsLock.Lock()
+	defer e.windowsLock.Unlock()
+	return e.context
+}
+
+// Windows returns the open windows of the application.
+func (e *ElectronApplication) Windows() []*Page {
+	e.windowsLock.Lock()
+	defer e.windowsLock.Unlock()
+	windows := make([]*Page, len(e.windows))
+	copy(windows, e.windows)
+	return windows
+}
+
+// FirstWindow returns the first window of the application, it waits for it
+// if it was not opened yet.
+func (e *ElectronApplication) FirstWindow() (*Page, error) {
+	future := e.EventFuture("window", nil)
+	defer future.Cancel()
+	if windows := e.Windows(); len(windows) > 0 {
+		return windows[0], nil
+	}
+	timeoutSettings := e.connection.timeoutSettings
+	if context := e.Context(); context != nil {
+		timeoutSettings = context.timeoutSettings
+	}
+	window, err := future.Wait(time.Duration(timeoutSettings.Timeout()) * time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	return window.(*Page), nil
+}
+
+// Evaluate evaluates the expression in the main process of the application,
+// a function gets the electron module as its first argument.
+func (e *ElectronApplication) Evaluate(expression string, options ...interface{}) (interface{}, error) {
+	var arg interface{}
+	forceExpression := false
+	if !isFunctionBody(expression) {
+		forceExpression = true
+	}
+	if len(options) == 1 {
+		arg = options[0]
+	} else if len(options) == 2 {
+		arg = options[0]
+		forceExpression = options[1].(bool)
+	}
+	result, err := e.channel.Send("evaluateExpression", map[string]interface{}{
+		"expression": expression,
+		"isFunction": !forceExpression,
+		"arg":        serializeArgument(arg),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseResult(result), nil
+}
+
+// Close closes the application and its windows.
+func (e *ElectronApplication) Close() error {
+	_, err := e.channel.Send("close")
+	return err
+}
+
+func newElectronApplication(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *ElectronApplication {
+	bt := &ElectronApplication{}
+	bt.createChannelOwner(bt, parent, objectType, guid, initializer)
+	if context, ok := initializer["context"]; ok {
+		bt.context = fromChannel(context).(*BrowserContext)
+	}
+	bt.channel.On("context", func(ev map[string]interface{}) {
+		bt.windowsLock.Lock()
+		defer bt.windowsLock.Unlock()
+		bt.context = fromChannel(ev["context"]).(*BrowserContext)
+	})
+	bt.channel.On("window", func(ev map[string]interface{}) {
+		page := fromChannel(ev["page"]).(*Page)
+		bt.windowsLock.Lock()
+		bt.windows = append(bt.windows, page)
+		bt.windowsLock.Unlock()
+		page.Once("close", func() {
+			bt.windowsLock.Lock()
+			defer bt.windowsLock.Unlock()
+			windows := make([]*Page, 0)
+			for _, window := range bt.windows {
+				if window != page {
+					windows = append(windows, window)
+				}
+			}
+			bt.windows = windows
+		})
+		bt.Emit("window", page)
+	})
+	bt.channel.On("close", func() {
+		bt.Emit("close")
+	})
+	return bt
+}
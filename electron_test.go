@@ -0,0 +1,58 @@
+package playwright
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestElectronLaunch(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Electron",
+			"guid":        "Electron",
+			"initializer": map[string]interface{}{},
+		},
+	})
+	electron := fromChannel(connection.objects["Electron"].channel).(*Electron)
+	launched := make(chan map[string]interface{}, 1)
+	go func() {
+		message := <-fake.sent
+		launched <- message
+		connection.Dispatch(&Message{
+			GUID:   "Electron",
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type":        "ElectronApplication",
+				"guid":        "ElectronApplication@1",
+				"initializer": map[string]interface{}{},
+			},
+		})
+		connection.Dispatch(&Message{
+			ID: message["id"].(int),
+			Result: map[string]interface{}{
+				"electronApplication": map[string]interface{}{"guid": "ElectronApplication@1"},
+			},
+		})
+	}()
+	application, err := electron.Launch("/bin/electron", ElectronLaunchOptions{
+		Args: []string{"main.js"},
+		Env:  map[string]interface{}{"FOO": "bar"},
+	})
+	require.NoError(t, err)
+	message := <-launched
+	require.Equal(t, "launch", message["method"])
+	require.Equal(t, map[string]interface{}{
+		"executablePath": "/bin/electron",
+		"args":           []interface{}{"main.js"},
+		"env":            []interface{}{map[string]interface{}{"name": "FOO", "value": "bar"}},
+	}, message["params"])
+	require.Empty(t, application.Windows())
+
+	connection.timeoutSettings.SetTimeout(10)
+	_, err = application.FirstWindow()
+	require.True(t, errors.Is(err, ErrTimeout))
+}
@@ -1,6 +1,7 @@
 package playwright
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
@@ -88,17 +89,32 @@ func (e *ElementHandle) DispatchEvent(typ string, initObjects ...interface{}) er
 }
 
 func (e *ElementHandle) Hover(options ...ElementHandleHoverOptions) error {
-	_, err := e.channel.Send("hover", options)
+	return e.HoverContext(context.Background(), options...)
+}
+
+// HoverContext is like Hover but stops waiting once ctx is done.
+func (e *ElementHandle) HoverContext(ctx context.Context, options ...ElementHandleHoverOptions) error {
+	_, err := e.channel.SendContext(ctx, "hover", options)
 	return err
 }
 
 func (e *ElementHandle) Click(options ...ElementHandleClickOptions) error {
-	_, err := e.channel.Send("click", options)
+	return e.ClickContext(context.Background(), options...)
+}
+
+// ClickContext is like Click but stops waiting once ctx is done.
+func (e *ElementHandle) ClickContext(ctx context.Context, options ...ElementHandleClickOptions) error {
+	_, err := e.channel.SendContext(ctx, "click", options)
 	return err
 }
 
 func (e *ElementHandle) DblClick(options ...ElementHandleDblclickOptions) error {
-	_, err := e.channel.Send("dblclick", options)
+	return e.DblClickContext(context.Background(), options...)
+}
+
+// DblClickContext is like DblClick but stops waiting once ctx is done.
+func (e *ElementHandle) DblClickContext(ctx context.Context, options ...ElementHandleDblclickOptions) error {
+	_, err := e.channel.SendContext(ctx, "dblclick", options)
 	return err
 }
 
@@ -175,7 +191,13 @@ func (e *ElementHandle) EvaluateOnSelectorAll(selector string, expression string
 }
 
 func (e *ElementHandle) ScrollIntoViewIfNeeded(options ...ElementHandleScrollIntoViewIfNeededOptions) error {
-	_, err := e.channel.Send("scrollIntoViewIfNeeded", options)
+	return e.ScrollIntoViewIfNeededContext(context.Background(), options...)
+}
+
+// ScrollIntoViewIfNeededContext is like ScrollIntoViewIfNeeded but stops
+// waiting once ctx is done.
+func (e *ElementHandle) ScrollIntoViewIfNeededContext(ctx context.Context, options ...ElementHandleScrollIntoViewIfNeededOptions) error {
+	_, err := e.channel.SendContext(ctx, "scrollIntoViewIfNeeded", options)
 	if err != nil {
 		return err
 	}
@@ -183,7 +205,13 @@ func (e *ElementHandle) ScrollIntoViewIfNeeded(options ...ElementHandleScrollInt
 }
 
 func (e *ElementHandle) SetInputFiles(files []InputFile, options ...ElementHandleSetInputFilesOptions) error {
-	_, err := e.channel.Send("setInputFiles", map[string]interface{}{
+	return e.SetInputFilesContext(context.Background(), files, options...)
+}
+
+// SetInputFilesContext is like SetInputFiles but stops waiting once ctx
+// is done.
+func (e *ElementHandle) SetInputFilesContext(ctx context.Context, files []InputFile, options ...ElementHandleSetInputFilesOptions) error {
+	_, err := e.channel.SendContext(ctx, "setInputFiles", map[string]interface{}{
 		"files": normalizeFilePayloads(files),
 	}, options)
 	return err
@@ -200,50 +228,90 @@ func (e *ElementHandle) BoundingBox() (*Rect, error) {
 }
 
 func (e *ElementHandle) Check(options ...ElementHandleCheckOptions) error {
-	_, err := e.channel.Send("check", options)
+	return e.CheckContext(context.Background(), options...)
+}
+
+// CheckContext is like Check but stops waiting once ctx is done.
+func (e *ElementHandle) CheckContext(ctx context.Context, options ...ElementHandleCheckOptions) error {
+	_, err := e.channel.SendContext(ctx, "check", options)
 	return err
 }
 
 func (e *ElementHandle) Uncheck(options ...ElementHandleUncheckOptions) error {
-	_, err := e.channel.Send("uncheck", options)
+	return e.UncheckContext(context.Background(), options...)
+}
+
+// UncheckContext is like Uncheck but stops waiting once ctx is done.
+func (e *ElementHandle) UncheckContext(ctx context.Context, options ...ElementHandleUncheckOptions) error {
+	_, err := e.channel.SendContext(ctx, "uncheck", options)
 	return err
 }
 
 func (e *ElementHandle) Press(options ...ElementHandlePressOptions) error {
-	_, err := e.channel.Send("press", options)
+	return e.PressContext(context.Background(), options...)
+}
+
+// PressContext is like Press but stops waiting once ctx is done.
+func (e *ElementHandle) PressContext(ctx context.Context, options ...ElementHandlePressOptions) error {
+	_, err := e.channel.SendContext(ctx, "press", options)
 	return err
 }
 
 func (e *ElementHandle) Fill(value string, options ...ElementHandleFillOptions) error {
-	_, err := e.channel.Send("fill", map[string]interface{}{
+	return e.FillContext(context.Background(), value, options...)
+}
+
+// FillContext is like Fill but stops waiting once ctx is done.
+func (e *ElementHandle) FillContext(ctx context.Context, value string, options ...ElementHandleFillOptions) error {
+	_, err := e.channel.SendContext(ctx, "fill", map[string]interface{}{
 		"value": value,
 	}, options)
 	return err
 }
 
 func (e *ElementHandle) Type(value string, options ...ElementHandleTypeOptions) error {
-	_, err := e.channel.Send("type", map[string]interface{}{
+	return e.TypeContext(context.Background(), value, options...)
+}
+
+// TypeContext is like Type but stops waiting once ctx is done.
+func (e *ElementHandle) TypeContext(ctx context.Context, value string, options ...ElementHandleTypeOptions) error {
+	_, err := e.channel.SendContext(ctx, "type", map[string]interface{}{
 		"value": value,
 	}, options)
 	return err
 }
 
 func (e *ElementHandle) Focus() error {
-	_, err := e.channel.Send("focus")
+	return e.FocusContext(context.Background())
+}
+
+// FocusContext is like Focus but stops waiting once ctx is done.
+func (e *ElementHandle) FocusContext(ctx context.Context) error {
+	_, err := e.channel.SendContext(ctx, "focus")
 	return err
 }
 
 func (e *ElementHandle) SelectText(options ...ElementHandleSelectTextOptions) error {
-	_, err := e.channel.Send("selectText", options)
+	return e.SelectTextContext(context.Background(), options...)
+}
+
+// SelectTextContext is like SelectText but stops waiting once ctx is done.
+func (e *ElementHandle) SelectTextContext(ctx context.Context, options ...ElementHandleSelectTextOptions) error {
+	_, err := e.channel.SendContext(ctx, "selectText", options)
 	return err
 }
 
 func (e *ElementHandle) Screenshot(options ...ElementHandleScreenshotOptions) ([]byte, error) {
+	return e.ScreenshotContext(context.Background(), options...)
+}
+
+// ScreenshotContext is like Screenshot but stops waiting once ctx is done.
+func (e *ElementHandle) ScreenshotContext(ctx context.Context, options ...ElementHandleScreenshotOptions) ([]byte, error) {
 	var path *string
 	if len(options) > 0 {
 		path = options[0].Path
 	}
-	data, err := e.channel.Send("screenshot", options)
+	data, err := e.channel.SendContext(ctx, "screenshot", options)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message :%w", err)
 	}
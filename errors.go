@@ -1,28 +1,119 @@
 package playwright
 
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrDriverCrashed is returned by all pending and subsequent calls once the
+	// driver process, or the remote server of BrowserType.Connect, went away
+	// without Playwright.Stop being called. Browsers live inside the driver,
+	// so they have to be launched again with a new Run.
+	ErrDriverCrashed = errors.New("playwright driver crashed")
+	// ErrDriverUnresponsive is returned by all pending and subsequent calls
+	// once the driver did not reply to a heartbeat, see
+	// RunOptions.HeartbeatInterval.
+	ErrDriverUnresponsive = errors.New("playwright driver is unresponsive")
+	// ErrTimeout matches every TimeoutError with errors.Is, so callers can
+	// retry on timeouts without inspecting the error message.
+	ErrTimeout = errors.New("timeout")
+	// errConnectionClosed is returned by calls after the connection was stopped.
+	errConnectionClosed = errors.New("playwright connection closed")
+)
+
+// Error is an error which was reported by the driver.
 type Error struct {
+	// Name is the class of the error on the driver side, e.g. "Error".
+	Name    string
 	Message string
-	Stack   string
+	// Stack is the stack trace of the error inside the driver.
+	Stack string
 }
 
 func (e *Error) Error() string {
 	return e.Message
 }
 
+// TimeoutError is returned when a waiting operation exceeded its timeout.
 type TimeoutError Error
 
 func (e *TimeoutError) Error() string {
 	return e.Message
 }
 
+// Is reports whether target is ErrTimeout.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// TargetClosedError is returned when the page, context or browser of an
+// operation was closed before it finished. It unwraps to the *Error of the
+// driver.
+type TargetClosedError Error
+
+func (e *TargetClosedError) Error() string {
+	return e.Message
+}
+
+func (e *TargetClosedError) Unwrap() error {
+	return (*Error)(e)
+}
+
+// ProtocolError is returned when the browser rejected a protocol command of
+// the driver. It unwraps to the *Error of the driver.
+type ProtocolError Error
+
+func (e *ProtocolError) Error() string {
+	return e.Message
+}
+
+func (e *ProtocolError) Unwrap() error {
+	return (*Error)(e)
+}
+
+// targetClosedMessages are the messages with which the driver fails the
+// operations of a closed page, context or browser.
+var targetClosedMessages = []string{
+	"Target closed",
+	"Target page, context or browser has been closed",
+	"Browser has been closed",
+	"Browser closed",
+	"Navigation failed because page was closed!",
+}
+
+func isTargetClosedError(message string) bool {
+	for _, targetClosed := range targetClosedMessages {
+		if strings.Contains(message, targetClosed) {
+			return true
+		}
+	}
+	return false
+}
+
 func parseError(err errorPayload) error {
-	if err.Name == "TimeoutError" {
+	switch {
+	case err.Name == "TimeoutError":
 		return &TimeoutError{
+			Name:    err.Name,
+			Message: err.Message,
+			Stack:   err.Stack,
+		}
+	case isTargetClosedError(err.Message):
+		return &TargetClosedError{
+			Name:    err.Name,
+			Message: err.Message,
+			Stack:   err.Stack,
+		}
+	case strings.HasPrefix(err.Message, "Protocol error"):
+		return &ProtocolError{
+			Name:    err.Name,
 			Message: err.Message,
 			Stack:   err.Stack,
 		}
 	}
 	return &Error{
+		Name:    err.Name,
 		Message: err.Message,
 		Stack:   err.Stack,
 	}
@@ -0,0 +1,64 @@
+package playwright
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseError(t *testing.T) {
+	err := fmt.Errorf("could not send message to server: %w", parseError(errorPayload{
+		Name:    "TimeoutError",
+		Message: "Timeout 100ms exceeded.",
+		Stack:   "at foo.js:1",
+	}))
+	var timeoutErr *TimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	require.Equal(t, "TimeoutError", timeoutErr.Name)
+	require.Equal(t, "at foo.js:1", timeoutErr.Stack)
+
+	var targetClosedErr *TargetClosedError
+	require.True(t, errors.As(parseError(errorPayload{
+		Name:    "Error",
+		Message: "Protocol error (Runtime.callFunctionOn): Target closed.",
+	}), &targetClosedErr))
+
+	require.True(t, errors.As(parseError(errorPayload{
+		Name:    "Error",
+		Message: "Target page, context or browser has been closed",
+	}), &targetClosedErr))
+	// other closed things, e.g. a stream, are no closed targets
+	require.False(t, errors.As(parseError(errorPayload{
+		Name:    "Error",
+		Message: "Stream has been closed",
+	}), &targetClosedErr))
+
+	var protocolErr *ProtocolError
+	require.True(t, errors.As(parseError(errorPayload{
+		Name:    "Error",
+		Message: "Protocol error (Page.navigate): Cannot navigate to invalid URL",
+	}), &protocolErr))
+
+	// both still match the *Error of the driver
+	var playwrightErr *Error
+	require.True(t, errors.As(fmt.Errorf("wrapped: %w", targetClosedErr), &playwrightErr))
+	require.Equal(t, "Target page, context or browser has been closed", playwrightErr.Message)
+	require.True(t, errors.As(protocolErr, &playwrightErr))
+	require.Equal(t, "Protocol error (Page.navigate): Cannot navigate to invalid URL", playwrightErr.Message)
+
+	err = parseError(errorPayload{Name: "Error", Message: "foo"})
+	require.True(t, errors.As(err, &playwrightErr))
+	require.False(t, errors.As(err, &timeoutErr))
+	require.Equal(t, "foo", err.Error())
+}
+
+func TestErrTimeout(t *testing.T) {
+	err := fmt.Errorf("could not send message to server: %w", parseError(errorPayload{
+		Name:    "TimeoutError",
+		Message: "Timeout 100ms exceeded.",
+	}))
+	require.True(t, errors.Is(err, ErrTimeout))
+	require.False(t, errors.Is(parseError(errorPayload{Name: "Error", Message: "foo"}), ErrTimeout))
+}
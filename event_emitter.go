@@ -1,55 +1,234 @@
 package playwright
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"sync"
 )
 
 type (
+	// listener is a registered handler, its identity allows to remove exactly
+	// this registration even if the same function is registered twice.
+	listener struct {
+		handler interface{}
+		once    bool
+		// priority orders the listeners of an event, higher first
+		priority int
+		// onRemove is called after the listener was removed
+		onRemove func()
+	}
 	eventRegister struct {
-		once []interface{}
-		on   []interface{}
+		// listeners by descending priority, in the order of their registration
+		// within the same priority
+		listeners []*listener
 	}
 	EventEmitter struct {
 		sync.Mutex
 		events              map[string]*eventRegister
 		addEventHandlers    []func(name string, handler interface{})
 		removeEventHandlers []func(name string, handler interface{})
+		errorHandler        func(name string, err error)
+		maxListeners        int
+		maxListenersHook    func(name string, count int)
+		maxListenersWarned  map[string]bool
+		// asyncQueueSize enables the asynchronous delivery, see SetAsyncEmit
+		asyncQueueSize int
+		queuesLock     sync.Mutex
+		queuesCond     *sync.Cond
+		queues         map[string]*eventQueue
+	}
+	// eventQueue holds the pending deliveries of one event, which are
+	// delivered in order by a goroutine which exits once the queue is empty.
+	eventQueue struct {
+		deliveries []func()
+		running    bool
 	}
 )
 
-func (e *EventEmitter) Emit(name string, payload ...interface{}) {
+// HandlerPanicError is passed to the error handler of an EventEmitter when an
+// event handler panicked.
+type HandlerPanicError struct {
+	// Event is the name of the event which was handled.
+	Event string
+	// Value is the value which was passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("panic in %q event handler: %v", e.Event, e.Value)
+}
+
+// SetErrorHandler sets the function which gets the panics of event handlers as
+// HandlerPanicError. The other handlers of the event are called regardless.
+// By default the panics are logged.
+func (e *EventEmitter) SetErrorHandler(handler func(name string, err error)) {
 	e.Lock()
 	defer e.Unlock()
-	if _, ok := e.events[name]; !ok {
+	e.errorHandler = handler
+}
+
+func (e *EventEmitter) handleError(name string, err error) {
+	e.Lock()
+	errorHandler := e.errorHandler
+	e.Unlock()
+	if errorHandler != nil {
+		errorHandler(name, err)
 		return
 	}
+	log.Printf("playwright: %v\n%s", err, err.(*HandlerPanicError).Stack)
+}
+
+// SetAsyncEmit enables the asynchronous delivery of events: Emit queues the
+// payload and returns, the handlers are called on another goroutine. Events
+// of the same name are delivered in the order they were emitted. Emit blocks
+// while queueSize payloads of the event are pending. A queueSize of 0 switches
+// back to the synchronous delivery.
+func (e *EventEmitter) SetAsyncEmit(queueSize int) {
+	e.Lock()
+	defer e.Unlock()
+	e.asyncQueueSize = queueSize
+}
+
+// wildcardEvent is the name of the listeners which receive all events, with
+// the name of the event as the first argument, e.g.
+//
+//	page.On("*", func(name string, payload ...interface{}) {})
+const wildcardEvent = "*"
+
+// Emit calls the handlers of the name event with the payload. It returns the
+// number of handlers of the event, which are called or queued for the
+// asynchronous delivery, so that callers can fall back to a default behavior
+// when nothing is listening. The "*" handlers are not counted, as they only
+// observe the events.
+func (e *EventEmitter) Emit(name string, payload ...interface{}) int {
+	e.Lock()
+	listeners := e.takeListeners(name)
+	var wildcardListeners []*listener
+	if name != wildcardEvent {
+		wildcardListeners = e.takeListeners(wildcardEvent)
+	}
+	asyncQueueSize := e.asyncQueueSize
+	e.Unlock()
+	if len(listeners) == 0 && len(wildcardListeners) == 0 {
+		return 0
+	}
 
 	payloadV := make([]reflect.Value, 0)
 
 	for _, p := range payload {
 		payloadV = append(payloadV, reflect.ValueOf(p))
 	}
+	wildcardPayloadV := append([]reflect.Value{reflect.ValueOf(name)}, payloadV...)
 
-	for _, handler := range e.events[name].on {
-		handlerV := reflect.ValueOf(handler)
-		handlerV.Call(payloadV[:handlerV.Type().NumIn()])
+	deliver := func() {
+		for _, l := range listeners {
+			if err := callHandler(name, l.handler, payloadV); err != nil {
+				e.handleError(name, err)
+			}
+		}
+		for _, l := range wildcardListeners {
+			if err := callHandler(name, l.handler, wildcardPayloadV); err != nil {
+				e.handleError(name, err)
+			}
+		}
 	}
-	for _, handler := range e.events[name].once {
-		handlerV := reflect.ValueOf(handler)
-		handlerV.Call(payloadV[:handlerV.Type().NumIn()])
+	if asyncQueueSize > 0 {
+		e.enqueue(name, asyncQueueSize, deliver)
+		return len(listeners)
+	}
+	deliver()
+	return len(listeners)
+}
+
+func (e *EventEmitter) enqueue(name string, queueSize int, deliver func()) {
+	e.queuesLock.Lock()
+	defer e.queuesLock.Unlock()
+	if e.queues == nil {
+		e.queues = make(map[string]*eventQueue)
+		e.queuesCond = sync.NewCond(&e.queuesLock)
+	}
+	queue, ok := e.queues[name]
+	if !ok {
+		queue = &eventQueue{}
+		e.queues[name] = queue
+	}
+	for len(queue.deliveries) >= queueSize {
+		e.queuesCond.Wait()
+	}
+	queue.deliveries = append(queue.deliveries, deliver)
+	if !queue.running {
+		queue.running = true
+		go e.drain(queue)
+	}
+}
+
+func (e *EventEmitter) drain(queue *eventQueue) {
+	for {
+		e.queuesLock.Lock()
+		if len(queue.deliveries) == 0 {
+			queue.running = false
+			e.queuesLock.Unlock()
+			return
+		}
+		deliver := queue.deliveries[0]
+		queue.deliveries = queue.deliveries[1:]
+		e.queuesCond.Broadcast()
+		e.queuesLock.Unlock()
+		deliver()
 	}
-	e.events[name].once = make([]interface{}, 0)
 }
 
 func (e *EventEmitter) Once(name string, handler interface{}) {
 	e.addEvent(name, handler, true)
 }
 
+// On registers a handler for the name event. Handlers for "*" receive all
+// events with the name of the event before the payload. Handlers are called
+// in the order of their registration.
 func (e *EventEmitter) On(name string, handler interface{}) {
 	e.addEvent(name, handler, false)
 }
 
+// OnWithPriority registers the handler like On but calls it before the
+// handlers of a lower priority, On uses priority 0. Handlers of the same
+// priority are called in the order of their registration. It allows e.g.
+// interception handlers to run before the observing ones.
+func (e *EventEmitter) OnWithPriority(name string, priority int, handler interface{}) {
+	e.addListener(name, &listener{
+		handler:  handler,
+		priority: priority,
+	})
+}
+
+// OnWithContext is like On but the handler is removed once ctx is done, e.g.
+// at the end of a request which registered it.
+func (e *EventEmitter) OnWithContext(ctx context.Context, name string, handler interface{}) {
+	removed := make(chan struct{})
+	var removedOnce sync.Once
+	l := &listener{
+		handler: handler,
+		onRemove: func() {
+			removedOnce.Do(func() {
+				close(removed)
+			})
+		},
+	}
+	e.addListener(name, l)
+	go func() {
+		select {
+		case <-ctx.Done():
+			e.removeListener(name, l)
+		case <-removed:
+		}
+	}()
+}
+
 func (e *EventEmitter) addEventHandler(handler func(name string, handler interface{})) {
 	e.addEventHandlers = append(e.addEventHandlers, handler)
 }
@@ -58,66 +237,304 @@ func (e *EventEmitter) removeEventHandler(handler func(name string, handler inte
 	e.removeEventHandlers = append(e.removeEventHandlers, handler)
 }
 
+// RemoveListener removes all registrations of handler for the name event.
+// Closures which are created by the same function literal can not be told
+// apart, so all of them are removed.
 func (e *EventEmitter) RemoveListener(name string, handler interface{}) {
+	handlerPtr := reflect.ValueOf(handler).Pointer()
+	e.removeListeners(name, handler, func(l *listener) bool {
+		return reflect.ValueOf(l.handler).Pointer() == handlerPtr
+	})
+}
+
+// removeListener removes exactly the given registration.
+func (e *EventEmitter) removeListener(name string, l *listener) {
+	e.removeListeners(name, l.handler, func(other *listener) bool {
+		return other == l
+	})
+}
+
+func (e *EventEmitter) removeListeners(name string, handler interface{}, matches func(l *listener) bool) {
 	for _, mitm := range e.removeEventHandlers {
 		mitm(name, handler)
 	}
 	e.Lock()
-	defer e.Unlock()
 	if _, ok := e.events[name]; !ok {
+		e.Unlock()
 		return
 	}
-	handlerPtr := reflect.ValueOf(handler).Pointer()
-
-	onHandlers := []interface{}{}
-	for idx := range e.events[name].on {
-		eventPtr := reflect.ValueOf(e.events[name].on[idx]).Pointer()
-		if eventPtr != handlerPtr {
-			onHandlers = append(onHandlers, e.events[name].on[idx])
+	listeners := []*listener{}
+	removed := []*listener{}
+	for _, l := range e.events[name].listeners {
+		if matches(l) {
+			removed = append(removed, l)
+		} else {
+			listeners = append(listeners, l)
 		}
 	}
-	e.events[name].on = onHandlers
-
-	onceHandlers := []interface{}{}
-	for idx := range e.events[name].once {
-		eventPtr := reflect.ValueOf(e.events[name].once[idx]).Pointer()
-		if eventPtr != handlerPtr {
-			onceHandlers = append(onceHandlers, e.events[name].once[idx])
+	e.events[name].listeners = listeners
+	e.Unlock()
+	for _, l := range removed {
+		if l.onRemove != nil {
+			l.onRemove()
 		}
 	}
+}
 
-	e.events[name].once = onceHandlers
+// RemoveAllListeners removes all handlers of the given events, or of all
+// events if no name is given. Subscriptions get closed as well.
+func (e *EventEmitter) RemoveAllListeners(names ...string) {
+	e.Lock()
+	if len(names) == 0 {
+		for name := range e.events {
+			names = append(names, name)
+		}
+	}
+	removed := map[string][]*listener{}
+	for _, name := range names {
+		if register, ok := e.events[name]; ok {
+			removed[name] = register.listeners
+			delete(e.events, name)
+		}
+	}
+	e.Unlock()
+	for name, listeners := range removed {
+		for _, l := range listeners {
+			for _, mitm := range e.removeEventHandlers {
+				mitm(name, l.handler)
+			}
+			if l.onRemove != nil {
+				l.onRemove()
+			}
+		}
+	}
 }
 
+// ListenerCount returns the number of handlers of the name event.
 func (e *EventEmitter) ListenerCount(name string) int {
+	e.Lock()
+	defer e.Unlock()
+	if register, ok := e.events[name]; ok {
+		return len(register.listeners)
+	}
+	return 0
+}
+
+// TotalListeners returns the number of handlers of all events.
+func (e *EventEmitter) TotalListeners() int {
 	count := 0
 	e.Lock()
 	for key := range e.events {
-		count += len(e.events[key].on) + len(e.events[key].once)
+		count += len(e.events[key].listeners)
 	}
 	e.Unlock()
 	return count
 }
 
-func (e *EventEmitter) addEvent(name string, handler interface{}, once bool) {
+// ListenerNames returns the sorted names of the events which have handlers.
+func (e *EventEmitter) ListenerNames() []string {
+	e.Lock()
+	defer e.Unlock()
+	names := make([]string, 0, len(e.events))
+	for name, register := range e.events {
+		if len(register.listeners) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *EventEmitter) addEvent(name string, handler interface{}, once bool) *listener {
+	l := &listener{
+		handler: handler,
+		once:    once,
+	}
+	e.addListener(name, l)
+	return l
+}
+
+func (e *EventEmitter) addListener(name string, l *listener) {
 	for _, mitm := range e.addEventHandlers {
-		mitm(name, handler)
+		mitm(name, l.handler)
 	}
 	e.Lock()
 	if _, ok := e.events[name]; !ok {
 		e.events[name] = &eventRegister{
-			on:   make([]interface{}, 0),
-			once: make([]interface{}, 0),
+			listeners: make([]*listener, 0),
 		}
 	}
-	if once {
-		e.events[name].once = append(e.events[name].once, handler)
-	} else {
-		e.events[name].on = append(e.events[name].on, handler)
+	listeners := e.events[name].listeners
+	i := sort.Search(len(listeners), func(i int) bool {
+		return listeners[i].priority < l.priority
+	})
+	listeners = append(listeners, nil)
+	copy(listeners[i+1:], listeners[i:])
+	listeners[i] = l
+	e.events[name].listeners = listeners
+	count := len(e.events[name].listeners)
+	exceeded := e.maxListeners > 0 && count > e.maxListeners && !e.maxListenersWarned[name]
+	if exceeded {
+		if e.maxListenersWarned == nil {
+			e.maxListenersWarned = make(map[string]bool)
+		}
+		e.maxListenersWarned[name] = true
 	}
+	hook := e.maxListenersHook
 	e.Unlock()
+	if !exceeded {
+		return
+	}
+	if hook == nil {
+		log.Printf("playwright: possible listener leak, %d listeners for the %q event, see EventEmitter.SetMaxListeners", count, name)
+		return
+	}
+	hook(name, count)
+}
+
+// SetMaxListeners sets the number of handlers per event above which a possible
+// listener leak is reported, e.g. of code which registers handlers in a loop.
+// It is reported once per event, by the hook of SetMaxListenersHook or in the
+// log. 0, the default, disables the check.
+func (e *EventEmitter) SetMaxListeners(n int) {
+	e.Lock()
+	defer e.Unlock()
+	e.maxListeners = n
+}
+
+// SetMaxListenersHook sets the function which is called instead of logging
+// when the handlers of an event exceed the limit of SetMaxListeners.
+func (e *EventEmitter) SetMaxListenersHook(hook func(name string, count int)) {
+	e.Lock()
+	defer e.Unlock()
+	e.maxListenersHook = hook
+}
+
+func (e *EventEmitter) emitter() *EventEmitter {
+	return e
 }
 
 func (e *EventEmitter) initEventEmitter() {
 	e.events = make(map[string]*eventRegister)
 }
+
+// takeListeners returns the listeners of the name event and drops the ones
+// which are only called once. The emitter has to be locked.
+func (e *EventEmitter) takeListeners(name string) []*listener {
+	register, ok := e.events[name]
+	if !ok {
+		return nil
+	}
+	listeners := register.listeners
+	remaining := make([]*listener, 0, len(listeners))
+	for _, l := range listeners {
+		if !l.once {
+			remaining = append(remaining, l)
+		}
+	}
+	register.listeners = remaining
+	return listeners
+}
+
+// callHandler calls the handler and recovers from its panics, so that a broken
+// handler can not take down the connection.
+func callHandler(name string, handler interface{}, payloadV []reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &HandlerPanicError{
+				Event: name,
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+	handlerV := reflect.ValueOf(handler)
+	handlerT := handlerV.Type()
+	fixedIn := handlerT.NumIn()
+	if handlerT.IsVariadic() {
+		fixedIn--
+	}
+	args := make([]reflect.Value, 0, len(payloadV))
+	for i, arg := range payloadV {
+		var argT reflect.Type
+		if i < fixedIn {
+			argT = handlerT.In(i)
+		} else if handlerT.IsVariadic() {
+			argT = handlerT.In(fixedIn).Elem()
+		} else {
+			// the handler is not interested in the remaining payload
+			break
+		}
+		if !arg.IsValid() {
+			// nil payload
+			arg = reflect.Zero(argT)
+		}
+		args = append(args, arg)
+	}
+	for i := len(args); i < fixedIn; i++ {
+		args = append(args, reflect.Zero(handlerT.In(i)))
+	}
+	handlerV.Call(args)
+	return nil
+}
+
+// Subscribe returns a channel which receives the first payload value of every
+// name event, or nil for events without payload, and a function which
+// unsubscribes again and closes the channel. Events are queued, so a slow
+// receiver does not block the emitter.
+func (e *EventEmitter) Subscribe(name string) (<-chan interface{}, func()) {
+	events := make(chan interface{})
+	var (
+		pendingMu sync.Mutex
+		pending   []interface{}
+		wake      = make(chan struct{}, 1)
+		done      = make(chan struct{})
+	)
+	handler := func(payload ...interface{}) {
+		var value interface{}
+		if len(payload) > 0 {
+			value = payload[0]
+		}
+		pendingMu.Lock()
+		pending = append(pending, value)
+		pendingMu.Unlock()
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	var closeOnce sync.Once
+	l := &listener{
+		handler: handler,
+		onRemove: func() {
+			closeOnce.Do(func() {
+				close(done)
+			})
+		},
+	}
+	e.addListener(name, l)
+	go func() {
+		defer close(events)
+		for {
+			pendingMu.Lock()
+			batch := pending
+			pending = nil
+			pendingMu.Unlock()
+			for _, value := range batch {
+				select {
+				case events <- value:
+				case <-done:
+					return
+				}
+			}
+			select {
+			case <-wake:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return events, func() {
+		e.removeListener(name, l)
+	}
+}
@@ -0,0 +1,97 @@
+package playwright
+
+import "fmt"
+
+// EventSource is implemented by all objects which emit events, like Page,
+// BrowserContext and Browser.
+type EventSource interface {
+	On(name string, handler interface{})
+	Once(name string, handler interface{})
+	RemoveListener(name string, handler interface{})
+	emitter() *EventEmitter
+}
+
+// Event describes an event whose payload is a T, e.g. PageEventRequest. It
+// lets OnEvent check the type of the handler at compile time.
+type Event[T any] struct {
+	name string
+}
+
+// Name returns the name of the event, e.g. "request".
+func (e Event[T]) Name() string {
+	return e.name
+}
+
+func newEvent[T any](name string) Event[T] {
+	return Event[T]{name: name}
+}
+
+// The events with a payload. Events without one, like "close" of Page, are
+// registered with On.
+var (
+	PageEventConsole         = newEvent[*ConsoleMessage]("console")
+	PageEventDialog          = newEvent[*Dialog]("dialog")
+	PageEventDownload        = newEvent[*Download]("download")
+	PageEventFileChooser     = newEvent[*FileChooser]("filechooser")
+	PageEventFrameAttached   = newEvent[*Frame]("frameAttached")
+	PageEventFrameDetached   = newEvent[*Frame]("frameDetached")
+	PageEventFrameNavigated  = newEvent[*Frame]("framenavigated")
+	PageEventPopup           = newEvent[*Page]("popup")
+	PageEventRequest         = newEvent[*Request]("request")
+	PageEventRequestFailed   = newEvent[*Request]("requestfailed")
+	PageEventRequestFinished = newEvent[*Request]("requestfinished")
+	PageEventResponse        = newEvent[*Response]("response")
+	PageEventWorker          = newEvent[*Worker]("worker")
+
+	BrowserContextEventPage           = newEvent[*Page]("page")
+	BrowserContextEventBackgroundPage = newEvent[*Page]("backgroundpage")
+	BrowserContextEventServiceWorker  = newEvent[*Worker]("serviceworker")
+
+	BrowserEventDisconnected = newEvent[*Browser]("disconnected")
+
+	WorkerEventClose = newEvent[*Worker]("close")
+
+	ElectronApplicationEventWindow = newEvent[*Page]("window")
+)
+
+// OnEvent registers a handler with a typed payload, e.g.
+//
+//	playwright.OnEvent(page, playwright.PageEventRequest, func(request *playwright.Request) {})
+//
+// A handler of another type does not compile. It returns a function which
+// removes the handler again.
+func OnEvent[T any](source EventSource, event Event[T], handler func(T)) (remove func()) {
+	emitter := source.emitter()
+	l := emitter.addEvent(event.name, typedHandler(event.name, handler), false)
+	return func() {
+		emitter.removeListener(event.name, l)
+	}
+}
+
+// OnceEvent is like OnEvent but the handler is only called for the next event.
+func OnceEvent[T any](source EventSource, event Event[T], handler func(T)) (remove func()) {
+	emitter := source.emitter()
+	l := emitter.addEvent(event.name, typedHandler(event.name, handler), true)
+	return func() {
+		emitter.removeListener(event.name, l)
+	}
+}
+
+// typedHandler converts the payload for the handler. A payload of another type
+// panics, which is passed to the error handler of the emitter as a
+// HandlerPanicError.
+func typedHandler[T any](name string, handler func(T)) func(payload interface{}) {
+	return func(payload interface{}) {
+		if payload == nil {
+			var zero T
+			handler(zero)
+			return
+		}
+		typed, ok := payload.(T)
+		if !ok {
+			var expected T
+			panic(fmt.Sprintf("payload of %q event is %T instead of %T", name, payload, expected))
+		}
+		handler(typed)
+	}
+}
@@ -1,7 +1,10 @@
 package playwright
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -76,3 +79,279 @@ func TestEventEmitterRemoveKeepExisting(t *testing.T) {
 	handler.RemoveListener(testEventName, func(...interface{}) {})
 	require.Equal(t, 2, handler.ListenerCount(testEventName))
 }
+
+func TestOnEvent(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	errs := make(chan error, 1)
+	handler.SetErrorHandler(func(name string, err error) {
+		errs <- err
+	})
+	values := make(chan int, 2)
+	remove := OnEvent(handler, newEvent[int](testEventName), func(value int) {
+		values <- value
+	})
+	require.Equal(t, 1, handler.ListenerCount(testEventName))
+	handler.Emit(testEventName, 123)
+	require.Equal(t, 123, <-values)
+	// a payload of another type is reported instead of skipped
+	handler.Emit(testEventName, "not an int")
+	require.Len(t, values, 0)
+	var panicErr *HandlerPanicError
+	require.True(t, errors.As(<-errs, &panicErr))
+	require.Equal(t, `payload of "foobar" event is string instead of int`, panicErr.Value)
+	remove()
+	require.Equal(t, 0, handler.ListenerCount(testEventName))
+}
+
+func TestOnceEvent(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	values := make(chan string, 2)
+	OnceEvent(handler, newEvent[string](testEventName), func(value string) {
+		values <- value
+	})
+	handler.Emit(testEventName, "foo")
+	handler.Emit(testEventName, "bar")
+	require.Equal(t, "foo", <-values)
+	require.Len(t, values, 0)
+}
+
+func TestOnEventDescriptor(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	requests := make(chan *Request, 2)
+	OnEvent(handler, PageEventRequest, func(request *Request) {
+		requests <- request
+	})
+	require.Equal(t, "request", PageEventRequest.Name())
+	request := &Request{}
+	handler.Emit("request", request)
+	handler.Emit("request", nil)
+	require.Equal(t, request, <-requests)
+	require.Nil(t, <-requests)
+}
+
+func TestEventEmitterSubscribe(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	events, unsubscribe := handler.Subscribe(testEventName)
+	require.Equal(t, 1, handler.ListenerCount(testEventName))
+	// the emitter does not wait for the receiver
+	handler.Emit(testEventName, 1)
+	handler.Emit(testEventName, 2)
+	handler.Emit(testEventName)
+	require.Equal(t, 1, <-events)
+	require.Equal(t, 2, <-events)
+	require.Nil(t, <-events)
+	unsubscribe()
+	unsubscribe()
+	require.Equal(t, 0, handler.ListenerCount(testEventName))
+	_, ok := <-events
+	require.False(t, ok)
+}
+
+func TestEventEmitterSubscribeIndependently(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	first, unsubscribeFirst := handler.Subscribe(testEventName)
+	second, unsubscribeSecond := handler.Subscribe(testEventName)
+	defer unsubscribeSecond()
+	unsubscribeFirst()
+	_, ok := <-first
+	require.False(t, ok)
+	handler.Emit(testEventName, 1)
+	require.Equal(t, 1, <-second)
+}
+
+func TestEventEmitterRemoveAllListeners(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	removed := []string{}
+	handler.removeEventHandler(func(name string, handler interface{}) {
+		removed = append(removed, name)
+	})
+	handler.On(testEventName, func() {})
+	handler.On("other", func() {})
+	events, _ := handler.Subscribe(testEventName)
+	handler.RemoveAllListeners(testEventName)
+	require.Equal(t, []string{testEventName, testEventName}, removed)
+	require.Equal(t, 1, handler.ListenerCount("other"))
+	_, ok := <-events
+	require.False(t, ok)
+
+	handler.RemoveAllListeners()
+	require.Equal(t, 0, handler.ListenerCount("other"))
+}
+
+func TestEventEmitterListenerCount(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	handler.On(testEventName, func() {})
+	handler.Once(testEventName, func() {})
+	handler.On("other", func() {})
+	require.Equal(t, 2, handler.ListenerCount(testEventName))
+	require.Equal(t, 1, handler.ListenerCount("other"))
+	require.Equal(t, 0, handler.ListenerCount("unknown"))
+	require.Equal(t, 3, handler.TotalListeners())
+	require.Equal(t, []string{testEventName, "other"}, handler.ListenerNames())
+	handler.RemoveAllListeners("other")
+	require.Equal(t, []string{testEventName}, handler.ListenerNames())
+}
+
+func TestEventEmitterHandlerPanic(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	var handlerErr error
+	handler.SetErrorHandler(func(name string, err error) {
+		require.Equal(t, testEventName, name)
+		handlerErr = err
+	})
+	called := false
+	handler.On(testEventName, func() {
+		panic("boom")
+	})
+	handler.On(testEventName, func() {
+		called = true
+	})
+	handler.Emit(testEventName)
+	require.True(t, called)
+	panicErr, ok := handlerErr.(*HandlerPanicError)
+	require.True(t, ok)
+	require.Equal(t, "boom", panicErr.Value)
+	require.Contains(t, panicErr.Error(), "boom")
+}
+
+func TestEventEmitterAsyncEmit(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	handler.SetAsyncEmit(10)
+	release := make(chan struct{})
+	values := make(chan int, 10)
+	handler.On(testEventName, func(value int) {
+		<-release
+		values <- value
+	})
+	// Emit does not wait for the blocked handler
+	for i := 0; i < 5; i++ {
+		handler.Emit(testEventName, i)
+	}
+	close(release)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, i, <-values)
+	}
+}
+
+func TestEventEmitterAsyncEmitBounded(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	handler.SetAsyncEmit(1)
+	release := make(chan struct{})
+	handler.On(testEventName, func() {
+		<-release
+	})
+	handler.Emit(testEventName)
+	handler.Emit(testEventName)
+	emitted := make(chan struct{})
+	go func() {
+		handler.Emit(testEventName)
+		close(emitted)
+	}()
+	select {
+	case <-emitted:
+		t.Fatal("Emit did not wait for the full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-emitted
+}
+
+func TestEventEmitterWildcard(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	type event struct {
+		name    string
+		payload []interface{}
+	}
+	events := []event{}
+	handler.On("*", func(name string, payload ...interface{}) {
+		events = append(events, event{name, payload})
+	})
+	handler.Emit(testEventName, 1)
+	handler.Emit("close")
+	require.Len(t, events, 2)
+	require.Equal(t, event{testEventName, []interface{}{1}}, events[0])
+	require.Equal(t, "close", events[1].name)
+	require.Empty(t, events[1].payload)
+}
+
+func TestEventEmitterNilPayload(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	called := false
+	handler.On(testEventName, func(page *Page, extra string) {
+		require.Nil(t, page)
+		require.Equal(t, "", extra)
+		called = true
+	})
+	handler.Emit(testEventName, nil)
+	require.True(t, called)
+}
+
+func TestEventEmitterOnWithContext(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	handler.OnWithContext(ctx, testEventName, func() {
+		calls++
+	})
+	handler.Emit(testEventName)
+	require.Equal(t, 1, calls)
+	cancel()
+	require.Eventually(t, func() bool {
+		return handler.ListenerCount(testEventName) == 0
+	}, time.Second, time.Millisecond)
+	handler.Emit(testEventName)
+	require.Equal(t, 1, calls)
+}
+
+func TestEventEmitterMaxListeners(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	handler.SetMaxListeners(2)
+	reports := []int{}
+	handler.SetMaxListenersHook(func(name string, count int) {
+		require.Equal(t, testEventName, name)
+		require.Equal(t, count, handler.ListenerCount(name))
+		reports = append(reports, count)
+	})
+	for i := 0; i < 4; i++ {
+		handler.On(testEventName, func() {})
+	}
+	require.Equal(t, []int{3}, reports)
+}
+
+func TestEventEmitterPriority(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	calls := []string{}
+	handler.On(testEventName, func() { calls = append(calls, "first") })
+	handler.OnWithPriority(testEventName, -1, func() { calls = append(calls, "low") })
+	handler.OnWithPriority(testEventName, 1, func() { calls = append(calls, "high") })
+	handler.On(testEventName, func() { calls = append(calls, "second") })
+	handler.OnWithPriority(testEventName, 1, func() { calls = append(calls, "high2") })
+	handler.Emit(testEventName)
+	require.Equal(t, []string{"high", "high2", "first", "second", "low"}, calls)
+}
+
+func TestEventEmitterEmitReturnsHandlerCount(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	handler.On("*", func(name string, payload ...interface{}) {})
+	require.Equal(t, 0, handler.Emit(testEventName))
+	handler.On(testEventName, func() {})
+	handler.Once(testEventName, func() {})
+	require.Equal(t, 2, handler.Emit(testEventName))
+	require.Equal(t, 1, handler.Emit(testEventName))
+}
@@ -0,0 +1,98 @@
+package playwright
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventFuture is the pending result of waiting for an event. It is created
+// before the action which triggers the event, so that the event can not be
+// missed.
+type EventFuture struct {
+	emitter  *EventEmitter
+	name     string
+	listener *listener
+	done     chan struct{}
+	once     sync.Once
+	payload  interface{}
+}
+
+// Done is closed once the event arrived.
+func (f *EventFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait returns the payload of the event. It returns a TimeoutError if the event
+// did not arrive within timeout, a timeout of 0 waits forever.
+func (f *EventFuture) Wait(timeout time.Duration) (interface{}, error) {
+	if timeout <= 0 {
+		<-f.done
+		return f.payload, nil
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-f.done:
+		return f.payload, nil
+	case <-timer.C:
+		f.Cancel()
+		return nil, &TimeoutError{
+			Name:    "TimeoutError",
+			Message: fmt.Sprintf("Timeout %s exceeded while waiting for event %q", timeout, f.name),
+		}
+	}
+}
+
+// WaitContext is like Wait without a timeout but returns ctx.Err() once ctx is
+// done before the event arrived.
+func (f *EventFuture) WaitContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.payload, nil
+	case <-ctx.Done():
+		f.Cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel stops waiting for the event.
+func (f *EventFuture) Cancel() {
+	f.emitter.removeListener(f.name, f.listener)
+}
+
+// EventFuture starts waiting for the next name event for which predicate, if
+// not nil, returns true.
+func (e *EventEmitter) EventFuture(name string, predicate func(payload interface{}) bool) *EventFuture {
+	future := &EventFuture{
+		emitter: e,
+		name:    name,
+		done:    make(chan struct{}),
+	}
+	future.listener = &listener{
+		handler: func(payload ...interface{}) {
+			var value interface{}
+			if len(payload) > 0 {
+				value = payload[0]
+			}
+			if predicate != nil && !predicate(value) {
+				return
+			}
+			future.once.Do(func() {
+				future.payload = value
+				close(future.done)
+				future.Cancel()
+			})
+		},
+	}
+	e.addListener(name, future.listener)
+	return future
+}
+
+// WaitForEventTimeout waits for the next name event for which predicate, if
+// not nil, returns true and returns its payload, or a TimeoutError after
+// timeout.
+func (e *EventEmitter) WaitForEventTimeout(name string, predicate func(payload interface{}) bool, timeout time.Duration) (interface{}, error) {
+	return e.EventFuture(name, predicate).Wait(timeout)
+}
@@ -0,0 +1,37 @@
+package playwright
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFuture(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	future := handler.EventFuture(testEventName, func(payload interface{}) bool {
+		return payload.(int) > 1
+	})
+	handler.Emit(testEventName, 1)
+	handler.Emit(testEventName, 2)
+	payload, err := future.Wait(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 2, payload)
+	require.Equal(t, 0, handler.ListenerCount(testEventName))
+}
+
+func TestWaitForEventTimeout(t *testing.T) {
+	handler := &EventEmitter{}
+	handler.initEventEmitter()
+	_, err := handler.WaitForEventTimeout(testEventName, nil, 10*time.Millisecond)
+	require.True(t, errors.Is(err, ErrTimeout))
+	require.Equal(t, 0, handler.ListenerCount(testEventName))
+
+	future := handler.EventFuture(testEventName, nil)
+	go handler.Emit(testEventName, "foo")
+	payload, err := future.Wait(0)
+	require.NoError(t, err)
+	require.Equal(t, "foo", payload)
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package playwright
+
+// fileLock is a no-op on platforms without advisory file locks.
+type fileLock struct{}
+
+func lockFile(path string) (*fileLock, error) {
+	return &fileLock{}, nil
+}
+
+func (l *fileLock) Unlock() error {
+	return nil
+}
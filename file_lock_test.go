@@ -0,0 +1,30 @@
+package playwright
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), ".install.lock")
+	lock, err := lockFile(lockPath)
+	require.NoError(t, err)
+	acquired := make(chan error)
+	var secondLock *fileLock
+	go func() {
+		var err error
+		secondLock, err = lockFile(lockPath)
+		acquired <- err
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("lock was acquired twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.NoError(t, lock.Unlock())
+	require.NoError(t, <-acquired)
+	require.NoError(t, secondLock.Unlock())
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package playwright
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileLock is an advisory lock which is shared between processes.
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile blocks until the exclusive lock on the given file is acquired.
+func lockFile(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("could not open lock file: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not acquire lock: %w", err)
+	}
+	return &fileLock{file: file}, nil
+}
+
+func (l *fileLock) Unlock() error {
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		l.file.Close()
+		return fmt.Errorf("could not release lock: %w", err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,46 @@
+package playwright
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const lockfileExclusiveLock = 0x00000002
+
+// fileLock is an advisory lock which is shared between processes.
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile blocks until the exclusive lock on the given file is acquired.
+func lockFile(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("could not open lock file: %w", err)
+	}
+	overlapped := &syscall.Overlapped{}
+	r1, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if r1 == 0 {
+		file.Close()
+		return nil, fmt.Errorf("could not acquire lock: %w", err)
+	}
+	return &fileLock{file: file}, nil
+}
+
+func (l *fileLock) Unlock() error {
+	overlapped := &syscall.Overlapped{}
+	r1, _, err := procUnlockFileEx.Call(l.file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if r1 == 0 {
+		l.file.Close()
+		return fmt.Errorf("could not release lock: %w", err)
+	}
+	return l.file.Close()
+}
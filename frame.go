@@ -1,6 +1,7 @@
 package playwright
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"reflect"
@@ -68,7 +69,12 @@ func (f *Frame) Name() string {
 }
 
 func (f *Frame) SetContent(content string, options ...PageSetContentOptions) error {
-	_, err := f.channel.Send("setContent", map[string]interface{}{
+	return f.SetContentContext(context.Background(), content, options...)
+}
+
+// SetContentContext is like SetContent but stops waiting once ctx is done.
+func (f *Frame) SetContentContext(ctx context.Context, content string, options ...PageSetContentOptions) error {
+	_, err := f.channel.SendContext(ctx, "setContent", map[string]interface{}{
 		"html": content,
 	}, options)
 	return err
@@ -80,7 +86,12 @@ func (f *Frame) Content() (string, error) {
 }
 
 func (f *Frame) Goto(url string, options ...PageGotoOptions) (*Response, error) {
-	channel, err := f.channel.Send("goto", map[string]interface{}{
+	return f.GotoContext(context.Background(), url, options...)
+}
+
+// GotoContext is like Goto but stops waiting once ctx is done.
+func (f *Frame) GotoContext(ctx context.Context, url string, options ...PageGotoOptions) (*Response, error) {
+	channel, err := f.channel.SendContext(ctx, "goto", map[string]interface{}{
 		"url": url,
 	}, options)
 	if err != nil {
@@ -167,6 +178,12 @@ func (f *Frame) WaitForEvent(event string, predicate ...interface{}) interface{}
 }
 
 func (f *Frame) WaitForNavigation(options ...PageWaitForNavigationOptions) (*Response, error) {
+	return f.WaitForNavigationContext(context.Background(), options...)
+}
+
+// WaitForNavigationContext is like WaitForNavigation but stops waiting once
+// ctx is done.
+func (f *Frame) WaitForNavigationContext(ctx context.Context, options ...PageWaitForNavigationOptions) (*Response, error) {
 	option := PageWaitForNavigationOptions{}
 	if len(options) == 1 {
 		option = options[0]
@@ -177,27 +194,36 @@ func (f *Frame) WaitForNavigation(options ...PageWaitForNavigationOptions) (*Res
 	if option.Timeout == nil {
 		option.Timeout = Int(f.page.timeoutSettings.NavigationTimeout())
 	}
-	deadline := time.After(time.Duration(*option.Timeout) * time.Millisecond)
+	var deadline <-chan time.Time
+	if *option.Timeout > 0 {
+		timer := time.NewTimer(time.Duration(*option.Timeout) * time.Millisecond)
+		defer timer.Stop()
+		deadline = timer.C
+	}
 	var matcher *urlMatcher
 	if option.Url != nil {
 		matcher = newURLMatcher(option.Url)
 	}
-	predicate := func(events ...interface{}) bool {
-		ev := events[0].(map[string]interface{})
-		if ev["error"] != nil {
-			print("error")
-		}
+	future := f.EventFuture("navigated", func(payload interface{}) bool {
+		ev := payload.(map[string]interface{})
 		return matcher == nil || matcher.Match(ev["url"].(string))
-	}
+	})
 	select {
 	case <-deadline:
-		return nil, fmt.Errorf("Timeout %dms exceeded.", *option.Timeout)
-	case eventData := <-f.WaitForEventCh("navigated", predicate):
-		event := eventData.(map[string]interface{})
-		if event["newDocument"] != nil && event["newDocument"].(map[string]interface{})["request"] != nil {
-			request := fromChannel(event["newDocument"].(map[string]interface{})["request"]).(*Request)
-			return request.Response()
+		future.Cancel()
+		return nil, &TimeoutError{
+			Name:    "TimeoutError",
+			Message: fmt.Sprintf("Timeout %dms exceeded.", *option.Timeout),
 		}
+	case <-ctx.Done():
+		future.Cancel()
+		return nil, ctx.Err()
+	case <-future.Done():
+	}
+	event := future.payload.(map[string]interface{})
+	if event["newDocument"] != nil && event["newDocument"].(map[string]interface{})["request"] != nil {
+		request := fromChannel(event["newDocument"].(map[string]interface{})["request"]).(*Request)
+		return request.Response()
 	}
 	return nil, nil
 }
@@ -241,6 +267,11 @@ func (f *Frame) QuerySelectorAll(selector string) ([]*ElementHandle, error) {
 }
 
 func (f *Frame) Evaluate(expression string, options ...interface{}) (interface{}, error) {
+	return f.EvaluateContext(context.Background(), expression, options...)
+}
+
+// EvaluateContext is like Evaluate but stops waiting once ctx is done.
+func (f *Frame) EvaluateContext(ctx context.Context, expression string, options ...interface{}) (interface{}, error) {
 	var arg interface{}
 	forceExpression := false
 	if !isFunctionBody(expression) {
@@ -252,7 +283,7 @@ func (f *Frame) Evaluate(expression string, options ...interface{}) (interface{}
 		arg = options[0]
 		forceExpression = options[1].(bool)
 	}
-	result, err := f.channel.Send("evaluateExpression", map[string]interface{}{
+	result, err := f.channel.SendContext(ctx, "evaluateExpression", map[string]interface{}{
 		"expression": expression,
 		"isFunction": !forceExpression,
 		"arg":        serializeArgument(arg),
@@ -339,14 +370,25 @@ func (f *Frame) EvaluateHandle(expression string, options ...interface{}) (inter
 }
 
 func (f *Frame) Click(selector string, options ...PageClickOptions) error {
-	_, err := f.channel.Send("click", map[string]interface{}{
+	return f.ClickContext(context.Background(), selector, options...)
+}
+
+// ClickContext is like Click but stops waiting once ctx is done.
+func (f *Frame) ClickContext(ctx context.Context, selector string, options ...PageClickOptions) error {
+	_, err := f.channel.SendContext(ctx, "click", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	return err
 }
 
 func (f *Frame) WaitForSelector(selector string, options ...PageWaitForSelectorOptions) (*ElementHandle, error) {
-	channel, err := f.channel.Send("waitForSelector", map[string]interface{}{
+	return f.WaitForSelectorContext(context.Background(), selector, options...)
+}
+
+// WaitForSelectorContext is like WaitForSelector but stops waiting once ctx
+// is done.
+func (f *Frame) WaitForSelectorContext(ctx context.Context, selector string, options ...PageWaitForSelectorOptions) (*ElementHandle, error) {
+	channel, err := f.channel.SendContext(ctx, "waitForSelector", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	if err != nil {
@@ -404,14 +446,25 @@ func (f *Frame) GetAttribute(selector string, name string, options ...PageGetAtt
 }
 
 func (f *Frame) Hover(selector string, options ...PageHoverOptions) error {
-	_, err := f.channel.Send("hover", map[string]interface{}{
+	return f.HoverContext(context.Background(), selector, options...)
+}
+
+// HoverContext is like Hover but stops waiting once ctx is done.
+func (f *Frame) HoverContext(ctx context.Context, selector string, options ...PageHoverOptions) error {
+	_, err := f.channel.SendContext(ctx, "hover", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	return err
 }
 
 func (e *Frame) SetInputFiles(selector string, files []InputFile, options ...FrameSetInputFilesOptions) error {
-	_, err := e.channel.Send("setInputFiles", map[string]interface{}{
+	return e.SetInputFilesContext(context.Background(), selector, files, options...)
+}
+
+// SetInputFilesContext is like SetInputFiles but stops waiting once ctx
+// is done.
+func (e *Frame) SetInputFilesContext(ctx context.Context, selector string, files []InputFile, options ...FrameSetInputFilesOptions) error {
+	_, err := e.channel.SendContext(ctx, "setInputFiles", map[string]interface{}{
 		"selector": selector,
 		"files":    normalizeFilePayloads(files),
 	}, options)
@@ -419,7 +472,12 @@ func (e *Frame) SetInputFiles(selector string, files []InputFile, options ...Fra
 }
 
 func (f *Frame) Type(selector, text string, options ...PageTypeOptions) error {
-	_, err := f.channel.Send("type", map[string]interface{}{
+	return f.TypeContext(context.Background(), selector, text, options...)
+}
+
+// TypeContext is like Type but stops waiting once ctx is done.
+func (f *Frame) TypeContext(ctx context.Context, selector, text string, options ...PageTypeOptions) error {
+	_, err := f.channel.SendContext(ctx, "type", map[string]interface{}{
 		"selector": selector,
 		"text":     text,
 	}, options)
@@ -427,7 +485,12 @@ func (f *Frame) Type(selector, text string, options ...PageTypeOptions) error {
 }
 
 func (f *Frame) Press(selector, key string, options ...PagePressOptions) error {
-	_, err := f.channel.Send("press", map[string]interface{}{
+	return f.PressContext(context.Background(), selector, key, options...)
+}
+
+// PressContext is like Press but stops waiting once ctx is done.
+func (f *Frame) PressContext(ctx context.Context, selector, key string, options ...PagePressOptions) error {
+	_, err := f.channel.SendContext(ctx, "press", map[string]interface{}{
 		"selector": selector,
 		"key":      key,
 	}, options)
@@ -435,14 +498,24 @@ func (f *Frame) Press(selector, key string, options ...PagePressOptions) error {
 }
 
 func (f *Frame) Check(selector string, options ...FrameCheckOptions) error {
-	_, err := f.channel.Send("check", map[string]interface{}{
+	return f.CheckContext(context.Background(), selector, options...)
+}
+
+// CheckContext is like Check but stops waiting once ctx is done.
+func (f *Frame) CheckContext(ctx context.Context, selector string, options ...FrameCheckOptions) error {
+	_, err := f.channel.SendContext(ctx, "check", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	return err
 }
 
 func (f *Frame) Uncheck(selector string, options ...FrameUncheckOptions) error {
-	_, err := f.channel.Send("uncheck", map[string]interface{}{
+	return f.UncheckContext(context.Background(), selector, options...)
+}
+
+// UncheckContext is like Uncheck but stops waiting once ctx is done.
+func (f *Frame) UncheckContext(ctx context.Context, selector string, options ...FrameUncheckOptions) error {
+	_, err := f.channel.SendContext(ctx, "uncheck", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	return err
@@ -488,14 +561,24 @@ func (f *Frame) ChildFrames() []*Frame {
 }
 
 func (f *Frame) DblClick(selector string, options ...FrameDblclickOptions) error {
-	_, err := f.channel.Send("dblclick", map[string]interface{}{
+	return f.DblClickContext(context.Background(), selector, options...)
+}
+
+// DblClickContext is like DblClick but stops waiting once ctx is done.
+func (f *Frame) DblClickContext(ctx context.Context, selector string, options ...FrameDblclickOptions) error {
+	_, err := f.channel.SendContext(ctx, "dblclick", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	return err
 }
 
 func (f *Frame) Fill(selector string, value string, options ...FrameFillOptions) error {
-	_, err := f.channel.Send("fill", map[string]interface{}{
+	return f.FillContext(context.Background(), selector, value, options...)
+}
+
+// FillContext is like Fill but stops waiting once ctx is done.
+func (f *Frame) FillContext(ctx context.Context, selector string, value string, options ...FrameFillOptions) error {
+	_, err := f.channel.SendContext(ctx, "fill", map[string]interface{}{
 		"selector": selector,
 		"value":    value,
 	}, options)
@@ -503,7 +586,12 @@ func (f *Frame) Fill(selector string, value string, options ...FrameFillOptions)
 }
 
 func (f *Frame) Focus(selector string, options ...FrameFocusOptions) error {
-	_, err := f.channel.Send("focus", map[string]interface{}{
+	return f.FocusContext(context.Background(), selector, options...)
+}
+
+// FocusContext is like Focus but stops waiting once ctx is done.
+func (f *Frame) FocusContext(ctx context.Context, selector string, options ...FrameFocusOptions) error {
+	_, err := f.channel.SendContext(ctx, "focus", map[string]interface{}{
 		"selector": selector,
 	}, options)
 	return err
@@ -1,7 +1,10 @@
 package playwright
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -69,3 +72,116 @@ func TestFrameSetInputFiles(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "file-to-upload.txt", fileName)
 }
+
+func TestFrameActionsContextCancelled(t *testing.T) {
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Frame",
+			"guid":        "Frame",
+			"initializer": map[string]interface{}{"name": "", "url": "about:blank"},
+		},
+	})
+	frame := fromChannel(connection.objects["Frame"].channel).(*Frame)
+	actions := map[string]func(ctx context.Context) error{
+		"hover": func(ctx context.Context) error {
+			return frame.HoverContext(ctx, "button")
+		},
+		"type": func(ctx context.Context) error {
+			return frame.TypeContext(ctx, "input", "foo")
+		},
+		"evaluateExpression": func(ctx context.Context) error {
+			_, err := frame.EvaluateContext(ctx, "() => new Promise(() => {})")
+			return err
+		},
+	}
+	for method, action := range actions {
+		ctx, cancel := context.WithCancel(context.Background())
+		sent := make(chan interface{}, 1)
+		go func() {
+			message := <-fake.sent
+			sent <- message["method"]
+			cancel()
+		}()
+		err := action(ctx)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Equal(t, method, <-sent)
+	}
+}
+
+func TestPageContextCancelled(t *testing.T) {
+	connection, fake := newFakeConnection()
+	create := func(parent, objectType, guid string, initializer map[string]interface{}) {
+		connection.Dispatch(&Message{
+			GUID:   parent,
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type":        objectType,
+				"guid":        guid,
+				"initializer": initializer,
+			},
+		})
+	}
+	create("", "BrowserContext", "BrowserContext", map[string]interface{}{})
+	create("BrowserContext", "Frame", "Frame", map[string]interface{}{"name": "", "url": "about:blank"})
+	create("BrowserContext", "Page", "Page", map[string]interface{}{
+		"mainFrame": map[string]interface{}{"guid": "Frame"},
+	})
+	create("Frame", "ElementHandle", "ElementHandle", map[string]interface{}{"preview": "JSHandle@node"})
+	browserContext := fromChannel(connection.objects["BrowserContext"].channel).(*BrowserContext)
+	page := fromChannel(connection.objects["Page"].channel).(*Page)
+	element := fromChannel(connection.objects["ElementHandle"].channel).(*ElementHandle)
+
+	actions := map[string]func(ctx context.Context) error{
+		"click": func(ctx context.Context) error {
+			return element.ClickContext(ctx)
+		},
+		"screenshot": func(ctx context.Context) error {
+			_, err := page.ScreenshotContext(ctx)
+			return err
+		},
+		"newPage": func(ctx context.Context) error {
+			_, err := browserContext.NewPageContext(ctx)
+			return err
+		},
+	}
+	for method, action := range actions {
+		ctx, cancel := context.WithCancel(context.Background())
+		sent := make(chan interface{}, 1)
+		go func() {
+			message := <-fake.sent
+			sent <- message["method"]
+			cancel()
+		}()
+		err := action(ctx)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Equal(t, method, <-sent)
+	}
+
+	waits := map[string]func(ctx context.Context) error{
+		"navigated": func(ctx context.Context) error {
+			_, err := page.WaitForNavigationContext(ctx, PageWaitForNavigationOptions{Timeout: Int(0)})
+			return err
+		},
+		"request": func(ctx context.Context) error {
+			_, err := page.WaitForRequestContext(ctx, "**/foo")
+			return err
+		},
+		"response": func(ctx context.Context) error {
+			_, err := page.WaitForResponseContext(ctx, "**/foo")
+			return err
+		},
+	}
+	for event, wait := range waits {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := wait(ctx)
+		cancel()
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		if event == "navigated" {
+			require.Zero(t, page.mainFrame.ListenerCount(event))
+		} else {
+			require.Zero(t, page.ListenerCount(event))
+		}
+	}
+}
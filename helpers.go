@@ -64,6 +64,10 @@ func transformStructIntoMapIfNeeded(inStruct interface{}) map[string]interface{}
 				// out of the field.
 				tagv := fi.Tag.Get("json")
 				key := strings.Split(tagv, ",")[0]
+				if key == "-" {
+					// Go only options which are not sent to the driver
+					continue
+				}
 				if key == "" {
 					key = fi.Name
 				}
@@ -97,7 +101,19 @@ func transformOptions(options ...interface{}) map[string]interface{} {
 		// Case 3: two values are given. The first one needs to be a map and the
 		// second one can be a struct or map. It will be then get merged into the first
 		// base map.
-		base = transformStructIntoMapIfNeeded(options[0])
+		first := options[0]
+		// The variadic options of a method are given as a slice, e.g.
+		// Launch(options ...BrowserTypeLaunchOptions).
+		if v := reflect.ValueOf(first); v.Kind() == reflect.Slice {
+			first = nil
+			if v.Len() > 0 {
+				first = v.Index(0).Interface()
+			}
+		}
+		base = make(map[string]interface{})
+		if first != nil {
+			base = transformStructIntoMapIfNeeded(first)
+		}
 		option = options[1]
 	}
 	v := reflect.ValueOf(option)
@@ -209,6 +225,20 @@ func newRouteHandlerEntry(matcher *urlMatcher, handler routeHandler) *routeHandl
 	}
 }
 
+// isRoutedBy reports whether the entry was added by Route with the url, the
+// same glob pattern or a *regexp.Regexp with the same expression. Functions
+// can not be compared, so a func url never matches.
+func (r *routeHandlerEntry) isRoutedBy(url interface{}) bool {
+	if reflect.ValueOf(url).Kind() == reflect.Func || reflect.ValueOf(r.matcher.urlOrPredicate).Kind() == reflect.Func {
+		return false
+	}
+	if regex, ok := url.(*regexp.Regexp); ok {
+		other, ok := r.matcher.urlOrPredicate.(*regexp.Regexp)
+		return ok && regex.String() == other.String()
+	}
+	return url == r.matcher.urlOrPredicate
+}
+
 type safeStringSet struct {
 	sync.Mutex
 	v []string
@@ -257,18 +287,24 @@ func newSafeStringSet(v []string) *safeStringSet {
 const DEFAULT_TIMEOUT = 30 * 1000
 
 type timeoutSettings struct {
-	parent            *timeoutSettings
-	timeout           int
-	navigationTimeout int
+	sync.Mutex
+	parent *timeoutSettings
+	// timeout and navigationTimeout are nil until they are set, the ones of
+	// the parent apply until then. 0 disables the timeout.
+	timeout           *int
+	navigationTimeout *int
 }
 
 func (t *timeoutSettings) SetTimeout(timeout int) {
-	t.timeout = timeout
+	t.Lock()
+	defer t.Unlock()
+	t.timeout = &timeout
 }
 
 func (t *timeoutSettings) Timeout() int {
-	if t.timeout != 0 {
-		return t.timeout
+	timeout, _ := t.explicit()
+	if timeout != nil {
+		return *timeout
 	}
 	if t.parent != nil {
 		return t.parent.Timeout()
@@ -277,12 +313,15 @@ func (t *timeoutSettings) Timeout() int {
 }
 
 func (t *timeoutSettings) SetNavigationTimeout(navigationTimeout int) {
-	t.navigationTimeout = navigationTimeout
+	t.Lock()
+	defer t.Unlock()
+	t.navigationTimeout = &navigationTimeout
 }
 
 func (t *timeoutSettings) NavigationTimeout() int {
-	if t.navigationTimeout != 0 {
-		return t.navigationTimeout
+	_, navigationTimeout := t.explicit()
+	if navigationTimeout != nil {
+		return *navigationTimeout
 	}
 	if t.parent != nil {
 		return t.parent.NavigationTimeout()
@@ -290,10 +329,16 @@ func (t *timeoutSettings) NavigationTimeout() int {
 	return DEFAULT_TIMEOUT
 }
 
+// explicit returns the timeouts which were set on these settings, nil if
+// they were not set.
+func (t *timeoutSettings) explicit() (timeout, navigationTimeout *int) {
+	t.Lock()
+	defer t.Unlock()
+	return t.timeout, t.navigationTimeout
+}
+
 func newTimeoutSettings(parent *timeoutSettings) *timeoutSettings {
 	return &timeoutSettings{
-		parent:            parent,
-		timeout:           DEFAULT_TIMEOUT,
-		navigationTimeout: DEFAULT_TIMEOUT,
+		parent: parent,
 	}
 }
@@ -1,6 +1,7 @@
 package playwright
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -94,3 +95,54 @@ func TestRemapMapToStruct(t *testing.T) {
 	remapMapToStruct(inMap, &ourStruct)
 	require.Equal(t, ourStruct.V1, "foobar")
 }
+
+func TestTimeoutSettings(t *testing.T) {
+	global := newTimeoutSettings(nil)
+	context := newTimeoutSettings(global)
+	page := newTimeoutSettings(context)
+	require.Equal(t, DEFAULT_TIMEOUT, page.Timeout())
+	require.Equal(t, DEFAULT_TIMEOUT, page.NavigationTimeout())
+
+	global.SetTimeout(1000)
+	global.SetNavigationTimeout(2000)
+	require.Equal(t, 1000, page.Timeout())
+	require.Equal(t, 2000, page.NavigationTimeout())
+
+	context.SetNavigationTimeout(3000)
+	page.SetTimeout(4000)
+	require.Equal(t, 4000, page.Timeout())
+	require.Equal(t, 3000, page.NavigationTimeout())
+
+	// 0 disables the timeout instead of falling back to the parent
+	context.SetTimeout(0)
+	page.SetNavigationTimeout(0)
+	require.Equal(t, 0, context.Timeout())
+	require.Equal(t, 0, page.NavigationTimeout())
+	timeout, navigationTimeout := global.explicit()
+	require.Equal(t, 1000, *timeout)
+	require.Equal(t, 2000, *navigationTimeout)
+}
+
+func TestTransformOptionsVariadicBase(t *testing.T) {
+	overrides := map[string]interface{}{
+		"userDataDir": "foo",
+	}
+	require.Equal(t, map[string]interface{}{
+		"headless":    Bool(true),
+		"userDataDir": "foo",
+	}, transformOptions([]BrowserTypeLaunchPersistentContextOptions{{Headless: Bool(true)}}, overrides))
+	require.Equal(t, overrides, transformOptions([]BrowserTypeLaunchPersistentContextOptions{}, overrides))
+}
+
+func TestRouteHandlerEntryIsRoutedBy(t *testing.T) {
+	handler := func(*Route, *Request) {}
+	predicate := func(url string) bool { return true }
+	for _, url := range []interface{}{"**/*.png", regexp.MustCompile(`\.png$`)} {
+		require.True(t, newRouteHandlerEntry(newURLMatcher(url), handler).isRoutedBy(url))
+	}
+	entry := newRouteHandlerEntry(newURLMatcher("**/*.png"), handler)
+	require.False(t, entry.isRoutedBy("**/*.jpg"))
+	require.False(t, entry.isRoutedBy(predicate))
+	require.False(t, newRouteHandlerEntry(newURLMatcher(predicate), handler).isRoutedBy(predicate))
+	require.True(t, newRouteHandlerEntry(newURLMatcher(regexp.MustCompile("a+")), handler).isRoutedBy(regexp.MustCompile("a+")))
+}
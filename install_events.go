@@ -0,0 +1,82 @@
+package playwright
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// Phases of an InstallEvent.
+const (
+	InstallPhaseDownloading = "downloading"
+	InstallPhaseInstalled   = "installed"
+)
+
+// InstallEvent describes the progress of a browser installation, see
+// InstallOptions.Events.
+type InstallEvent struct {
+	// Browser is the name of the browser, e.g. "chromium".
+	Browser string
+	// Phase is either InstallPhaseDownloading or InstallPhaseInstalled.
+	Phase string
+	// Percent of the download which is completed.
+	Percent int
+}
+
+var (
+	installProgressRegexp  = regexp.MustCompile(`^Downloading (\w+) .*?(\d+)%`)
+	installCompletedRegexp = regexp.MustCompile(`^(\w+) \S+ downloaded to `)
+)
+
+// parseInstallEvent parses a line of the driver output during the browser
+// installation.
+func parseInstallEvent(line string) (InstallEvent, bool) {
+	if match := installProgressRegexp.FindStringSubmatch(line); match != nil {
+		percent, _ := strconv.Atoi(match[2])
+		return InstallEvent{
+			Browser: match[1],
+			Phase:   InstallPhaseDownloading,
+			Percent: percent,
+		}, true
+	}
+	if match := installCompletedRegexp.FindStringSubmatch(line); match != nil {
+		return InstallEvent{
+			Browser: match[1],
+			Phase:   InstallPhaseInstalled,
+			Percent: 100,
+		}, true
+	}
+	return InstallEvent{}, false
+}
+
+// scanLinesOrCarriageReturns is a bufio.SplitFunc which also splits on \r,
+// since progress bars update their line that way.
+func scanLinesOrCarriageReturns(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// emitInstallEvents sends the events of the driver output to the channel
+// until the reader is closed. Repeated events get skipped.
+func emitInstallEvents(output io.Reader, events chan<- InstallEvent) {
+	scanner := bufio.NewScanner(output)
+	scanner.Split(scanLinesOrCarriageReturns)
+	var lastEvent InstallEvent
+	for scanner.Scan() {
+		event, ok := parseInstallEvent(scanner.Text())
+		if ok && event != lastEvent {
+			events <- event
+			lastEvent = event
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package playwright
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitInstallEvents(t *testing.T) {
+	output := "Downloading chromium v799411 - 117.5 Mb [====      ] 10% 8.3s\r" +
+		"Downloading chromium v799411 - 117.5 Mb [====      ] 10% 8.1s\r" +
+		"Downloading chromium v799411 - 117.5 Mb [==========] 100% 0.0s\n" +
+		"chromium v799411 downloaded to /root/.cache/ms-playwright/chromium-799411\n" +
+		"some unrelated output\n"
+	events := make(chan InstallEvent, 10)
+	emitInstallEvents(strings.NewReader(output), events)
+	close(events)
+	received := []InstallEvent{}
+	for event := range events {
+		received = append(received, event)
+	}
+	require.Equal(t, []InstallEvent{
+		{Browser: "chromium", Phase: InstallPhaseDownloading, Percent: 10},
+		{Browser: "chromium", Phase: InstallPhaseDownloading, Percent: 100},
+		{Browser: "chromium", Phase: InstallPhaseInstalled, Percent: 100},
+	}, received)
+}
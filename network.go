@@ -22,6 +22,9 @@ func parseHeaders(headers []interface{}) map[string]string {
 	return out
 }
 
+// NetworkCookie is a cookie of BrowserContext.Cookies. Expires is in seconds
+// since the Unix epoch, -1 for a session cookie. SameSite is "Strict", "Lax"
+// or "None".
 type NetworkCookie struct {
 	Name     string `json:"name"`
 	Value    string `json:"value"`
@@ -33,6 +36,8 @@ type NetworkCookie struct {
 	SameSite string `json:"sameSite"`
 }
 
+// SetNetworkCookieParam is a cookie of BrowserContext.AddCookies, it needs
+// either an URL or a Domain and a Path.
 type SetNetworkCookieParam struct {
 	Name     string  `json:"name"`
 	Value    string  `json:"value"`
@@ -10,6 +10,8 @@ func createObjectFactory(parent *ChannelOwner, objectType string, guid string, i
 		return newBrowserType(parent, objectType, guid, initializer)
 	case "BrowserContext":
 		return newBrowserContext(parent, objectType, guid, initializer)
+	case "CDPSession":
+		return newCDPSession(parent, objectType, guid, initializer)
 	case "ConsoleMessage":
 		return newConsoleMessage(parent, objectType, guid, initializer)
 	case "Dialog":
@@ -26,6 +28,8 @@ func createObjectFactory(parent *ChannelOwner, objectType string, guid string, i
 		return newPage(parent, objectType, guid, initializer)
 	case "Playwright":
 		return newPlaywright(parent, objectType, guid, initializer)
+	case "RemoteBrowser":
+		return newRemoteBrowser(parent, objectType, guid, initializer)
 	case "Request":
 		return newRequest(parent, objectType, guid, initializer)
 	case "Response":
@@ -37,7 +41,9 @@ func createObjectFactory(parent *ChannelOwner, objectType string, guid string, i
 	case "Selectors":
 		return nil
 	case "Electron":
-		return nil
+		return newElectron(parent, objectType, guid, initializer)
+	case "ElectronApplication":
+		return newElectronApplication(parent, objectType, guid, initializer)
 	default:
 		panic(objectType)
 	}
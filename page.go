@@ -1,9 +1,11 @@
 package playwright
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"reflect"
 	"sync"
 )
@@ -23,19 +25,49 @@ type Page struct {
 	routes          []*routeHandlerEntry
 	viewportSize    ViewportSize
 	ownedContext    *BrowserContext
+	videoMu         sync.Mutex
+	video           *Video
+	bindingsMu      sync.Mutex
+	bindings        map[string]BindingCallFunction
 }
 
 func (p *Page) Context() *BrowserContext {
 	return p.browserContext
 }
 
+// Video returns the recording of the page, it is nil unless the context was
+// created with the RecordVideo option.
+func (p *Page) Video() *Video {
+	if p.browserContext == nil || !p.browserContext.recordVideo {
+		return nil
+	}
+	return p.getVideo()
+}
+
+func (p *Page) getVideo() *Video {
+	p.videoMu.Lock()
+	defer p.videoMu.Unlock()
+	if p.video == nil {
+		p.video = newVideo(p)
+		if context, ok := p.parent.channel.object.(*BrowserContext); ok {
+			context.addVideo(p.video)
+		}
+	}
+	return p.video
+}
+
 func (p *Page) Close(options ...PageCloseOptions) error {
-	_, err := p.channel.Send("close", options)
+	return p.CloseContext(context.Background(), options...)
+}
+
+// CloseContext is like Close but stops waiting once ctx is done.
+func (p *Page) CloseContext(ctx context.Context, options ...PageCloseOptions) error {
+	_, err := p.channel.SendContext(ctx, "close", options)
 	if err != nil {
 		return err
 	}
 	if p.ownedContext != nil {
-		return p.ownedContext.Close()
+		return p.ownedContext.CloseContext(ctx)
 	}
 	return nil
 }
@@ -94,6 +126,12 @@ func (p *Page) WaitForSelector(selector string, options ...PageWaitForSelectorOp
 	return p.mainFrame.WaitForSelector(selector, options...)
 }
 
+// WaitForSelectorContext is like WaitForSelector but stops waiting once ctx
+// is done.
+func (p *Page) WaitForSelectorContext(ctx context.Context, selector string, options ...PageWaitForSelectorOptions) (*ElementHandle, error) {
+	return p.mainFrame.WaitForSelectorContext(ctx, selector, options...)
+}
+
 func (p *Page) DispatchEvent(selector string, typ string, options ...PageDispatchEventOptions) error {
 	return p.mainFrame.DispatchEvent(selector, typ, options...)
 }
@@ -102,6 +140,11 @@ func (p *Page) Evaluate(expression string, options ...interface{}) (interface{},
 	return p.mainFrame.Evaluate(expression, options...)
 }
 
+// EvaluateContext is like Evaluate but stops waiting once ctx is done.
+func (p *Page) EvaluateContext(ctx context.Context, expression string, options ...interface{}) (interface{}, error) {
+	return p.mainFrame.EvaluateContext(ctx, expression, options...)
+}
+
 func (p *Page) EvaluateHandle(expression string, options ...interface{}) (interface{}, error) {
 	return p.mainFrame.EvaluateHandle(expression, options...)
 }
@@ -122,6 +165,9 @@ func (p *Page) AddStyleTag(options PageAddStyleTagOptions) (*ElementHandle, erro
 	return p.mainFrame.AddStyleTag(options)
 }
 
+// SetExtraHTTPHeaders sets headers which are sent with every request of the
+// page, e.g. a rotated auth token. They are merged with the ones of
+// BrowserContext.SetExtraHTTPHeaders and replace the previous ones of the page.
 func (p *Page) SetExtraHTTPHeaders(headers map[string]string) error {
 	_, err := p.channel.Send("setExtraHTTPHeaders", map[string]interface{}{
 		"headers": serializeHeaders(headers),
@@ -141,12 +187,27 @@ func (p *Page) SetContent(content string, options ...PageSetContentOptions) erro
 	return p.mainFrame.SetContent(content, options...)
 }
 
+// SetContentContext is like SetContent but stops waiting once ctx is done.
+func (p *Page) SetContentContext(ctx context.Context, content string, options ...PageSetContentOptions) error {
+	return p.mainFrame.SetContentContext(ctx, content, options...)
+}
+
 func (p *Page) Goto(url string, options ...PageGotoOptions) (*Response, error) {
 	return p.mainFrame.Goto(url, options...)
 }
 
+// GotoContext is like Goto but stops waiting once ctx is done.
+func (p *Page) GotoContext(ctx context.Context, url string, options ...PageGotoOptions) (*Response, error) {
+	return p.mainFrame.GotoContext(ctx, url, options...)
+}
+
 func (p *Page) Reload(options ...PageReloadOptions) (*Response, error) {
-	response, err := p.channel.Send("reload", options)
+	return p.ReloadContext(context.Background(), options...)
+}
+
+// ReloadContext is like Reload but stops waiting once ctx is done.
+func (p *Page) ReloadContext(ctx context.Context, options ...PageReloadOptions) (*Response, error) {
+	response, err := p.channel.SendContext(ctx, "reload", options)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +219,12 @@ func (p *Page) WaitForLoadState(state ...string) {
 }
 
 func (p *Page) GoBack(options ...PageGoBackOptions) (*Response, error) {
-	channel, err := p.channel.Send("goBack", options)
+	return p.GoBackContext(context.Background(), options...)
+}
+
+// GoBackContext is like GoBack but stops waiting once ctx is done.
+func (p *Page) GoBackContext(ctx context.Context, options ...PageGoBackOptions) (*Response, error) {
+	channel, err := p.channel.SendContext(ctx, "goBack", options)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +236,12 @@ func (p *Page) GoBack(options ...PageGoBackOptions) (*Response, error) {
 }
 
 func (p *Page) GoForward(options ...PageGoForwardOptions) (*Response, error) {
-	resp, err := p.channel.Send("goForward", options)
+	return p.GoForwardContext(context.Background(), options...)
+}
+
+// GoForwardContext is like GoForward but stops waiting once ctx is done.
+func (p *Page) GoForwardContext(ctx context.Context, options ...PageGoForwardOptions) (*Response, error) {
+	resp, err := p.channel.SendContext(ctx, "goForward", options)
 	if err != nil {
 		return nil, err
 	}
@@ -209,6 +280,8 @@ func (p *Page) SetViewportSize(width, height int) error {
 	return nil
 }
 
+// ViewportSize returns the size of the viewport, it is zero for the pages of
+// a context with the NoViewport option.
 func (p *Page) ViewportSize() ViewportSize {
 	return p.viewportSize
 }
@@ -222,14 +295,29 @@ func (p *Page) Type(selector, text string, options ...PageTypeOptions) error {
 	return p.mainFrame.Type(selector, text, options...)
 }
 
+// TypeContext is like Type but stops waiting once ctx is done.
+func (p *Page) TypeContext(ctx context.Context, selector, text string, options ...PageTypeOptions) error {
+	return p.mainFrame.TypeContext(ctx, selector, text, options...)
+}
+
 func (p *Page) Fill(selector, text string, options ...FrameFillOptions) error {
 	return p.mainFrame.Fill(selector, text, options...)
 }
 
+// FillContext is like Fill but stops waiting once ctx is done.
+func (p *Page) FillContext(ctx context.Context, selector, text string, options ...FrameFillOptions) error {
+	return p.mainFrame.FillContext(ctx, selector, text, options...)
+}
+
 func (p *Page) Press(selector, key string, options ...PagePressOptions) error {
 	return p.mainFrame.Press(selector, key, options...)
 }
 
+// PressContext is like Press but stops waiting once ctx is done.
+func (p *Page) PressContext(ctx context.Context, selector, key string, options ...PagePressOptions) error {
+	return p.mainFrame.PressContext(ctx, selector, key, options...)
+}
+
 func (p *Page) Title() (string, error) {
 	return p.mainFrame.Title()
 }
@@ -241,11 +329,16 @@ func (p *Page) Workers() []*Worker {
 }
 
 func (p *Page) Screenshot(options ...PageScreenshotOptions) ([]byte, error) {
+	return p.ScreenshotContext(context.Background(), options...)
+}
+
+// ScreenshotContext is like Screenshot but stops waiting once ctx is done.
+func (p *Page) ScreenshotContext(ctx context.Context, options ...PageScreenshotOptions) ([]byte, error) {
 	var path *string
 	if len(options) > 0 {
 		path = options[0].Path
 	}
-	data, err := p.channel.Send("screenshot", options)
+	data, err := p.channel.SendContext(ctx, "screenshot", options)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message :%w", err)
 	}
@@ -262,11 +355,16 @@ func (p *Page) Screenshot(options ...PageScreenshotOptions) ([]byte, error) {
 }
 
 func (p *Page) PDF(options ...PagePdfOptions) ([]byte, error) {
+	return p.PDFContext(context.Background(), options...)
+}
+
+// PDFContext is like PDF but stops waiting once ctx is done.
+func (p *Page) PDFContext(ctx context.Context, options ...PagePdfOptions) ([]byte, error) {
 	var path *string
 	if len(options) > 0 {
 		path = options[0].Path
 	}
-	data, err := p.channel.Send("pdf", options)
+	data, err := p.channel.SendContext(ctx, "pdf", options)
 	if err != nil {
 		return nil, fmt.Errorf("could not send message :%w", err)
 	}
@@ -286,6 +384,11 @@ func (p *Page) Click(selector string, options ...PageClickOptions) error {
 	return p.mainFrame.Click(selector, options...)
 }
 
+// ClickContext is like Click but stops waiting once ctx is done.
+func (p *Page) ClickContext(ctx context.Context, selector string, options ...PageClickOptions) error {
+	return p.mainFrame.ClickContext(ctx, selector, options...)
+}
+
 func (p *Page) WaitForEvent(event string, predicate ...interface{}) interface{} {
 	evChan := make(chan interface{})
 	handler := func(ev ...interface{}) {
@@ -308,12 +411,26 @@ func (p *Page) WaitForNavigation(options ...PageWaitForNavigationOptions) (*Resp
 	return p.mainFrame.WaitForNavigation(options...)
 }
 
+// WaitForNavigationContext is like WaitForNavigation but stops waiting once
+// ctx is done.
+func (p *Page) WaitForNavigationContext(ctx context.Context, options ...PageWaitForNavigationOptions) (*Response, error) {
+	return p.mainFrame.WaitForNavigationContext(ctx, options...)
+}
+
 func (p *Page) WaitForRequest(url interface{}, options ...interface{}) *Request {
+	request, _ := p.WaitForRequestContext(context.Background(), url, options...)
+	return request
+}
+
+// WaitForRequestContext is like WaitForRequest but returns ctx.Err() once
+// ctx is done before a matching request arrived.
+func (p *Page) WaitForRequestContext(ctx context.Context, url interface{}, options ...interface{}) (*Request, error) {
 	var matcher *urlMatcher
 	if url != nil {
 		matcher = newURLMatcher(url)
 	}
-	predicate := func(req *Request) bool {
+	predicate := func(payload interface{}) bool {
+		req := payload.(*Request)
 		if matcher != nil {
 			return matcher.Match(req.URL())
 		}
@@ -322,24 +439,40 @@ func (p *Page) WaitForRequest(url interface{}, options ...interface{}) *Request
 		}
 		return true
 	}
-	return p.WaitForEvent("request", predicate).(*Request)
+	payload, err := p.EventFuture("request", predicate).WaitContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return payload.(*Request), nil
 }
 
 func (p *Page) WaitForResponse(url interface{}, options ...interface{}) *Response {
+	response, _ := p.WaitForResponseContext(context.Background(), url, options...)
+	return response
+}
+
+// WaitForResponseContext is like WaitForResponse but returns ctx.Err() once
+// ctx is done before a matching response arrived.
+func (p *Page) WaitForResponseContext(ctx context.Context, url interface{}, options ...interface{}) (*Response, error) {
 	var matcher *urlMatcher
 	if url != nil {
 		matcher = newURLMatcher(url)
 	}
-	predicate := func(req *Response) bool {
+	predicate := func(payload interface{}) bool {
+		res := payload.(*Response)
 		if matcher != nil {
-			return matcher.Match(req.URL())
+			return matcher.Match(res.URL())
 		}
 		if len(options) == 1 {
-			return reflect.ValueOf(options[0]).Call([]reflect.Value{reflect.ValueOf(req)})[0].Bool()
+			return reflect.ValueOf(options[0]).Call([]reflect.Value{reflect.ValueOf(res)})[0].Bool()
 		}
 		return true
 	}
-	return p.WaitForEvent("response", predicate).(*Response)
+	payload, err := p.EventFuture("response", predicate).WaitContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return payload.(*Response), nil
 }
 
 func (p *Page) ExpectEvent(event string, cb func() error, predicates ...interface{}) (interface{}, error) {
@@ -413,8 +546,7 @@ func (p *Page) ExpectWorker(cb func() error) (*Worker, error) {
 func (p *Page) Route(url interface{}, handler routeHandler) error {
 	p.routesMu.Lock()
 	defer p.routesMu.Unlock()
-	p.routes = append(p.routes, newRouteHandlerEntry(newURLMatcher(url), handler))
-	if len(p.routes) == 1 {
+	if len(p.routes) == 0 {
 		_, err := p.channel.Send("setNetworkInterceptionEnabled", map[string]interface{}{
 			"enabled": true,
 		})
@@ -422,6 +554,30 @@ func (p *Page) Route(url interface{}, handler routeHandler) error {
 			return err
 		}
 	}
+	p.routes = append(p.routes, newRouteHandlerEntry(newURLMatcher(url), handler))
+	return nil
+}
+
+// Unroute removes all routes of Route with the url, a glob pattern string or
+// a *regexp.Regexp with the same expression. Routes with a func(string) bool
+// url can not be removed, as Go can not compare functions.
+func (p *Page) Unroute(url interface{}) error {
+	p.routesMu.Lock()
+	defer p.routesMu.Unlock()
+	routes := make([]*routeHandlerEntry, 0)
+	for _, entry := range p.routes {
+		if !entry.isRoutedBy(url) {
+			routes = append(routes, entry)
+		}
+	}
+	removed := len(routes) != len(p.routes)
+	p.routes = routes
+	if removed && len(p.routes) == 0 {
+		_, err := p.channel.Send("setNetworkInterceptionEnabled", map[string]interface{}{
+			"enabled": false,
+		})
+		return err
+	}
 	return nil
 }
 
@@ -433,23 +589,62 @@ func (p *Page) Hover(selector string, options ...PageHoverOptions) error {
 	return p.mainFrame.Hover(selector, options...)
 }
 
+// HoverContext is like Hover but stops waiting once ctx is done.
+func (p *Page) HoverContext(ctx context.Context, selector string, options ...PageHoverOptions) error {
+	return p.mainFrame.HoverContext(ctx, selector, options...)
+}
+
 func (p *Page) Isclosed() bool {
 	return p.isClosed
 }
 
-func (b *Page) AddInitScript(options BrowserContextAddInitScriptOptions) error {
-	var source string
-	if options.Script != nil {
-		source = *options.Script
+// ExposeBinding adds the function window[name] to every frame of the page,
+// see BrowserContext.ExposeBinding.
+func (p *Page) ExposeBinding(name string, binding BindingCallFunction) error {
+	if p.browserContext != nil && p.browserContext.getBinding(name) != nil {
+		return fmt.Errorf("function \"%s\" has been already registered in the browser context", name)
 	}
-	if options.Path != nil {
-		content, err := ioutil.ReadFile(*options.Path)
-		if err != nil {
-			return err
-		}
-		source = string(content)
+	p.bindingsMu.Lock()
+	if _, ok := p.bindings[name]; ok {
+		p.bindingsMu.Unlock()
+		return fmt.Errorf("function \"%s\" has been already registered", name)
+	}
+	p.bindings[name] = binding
+	p.bindingsMu.Unlock()
+	_, err := p.channel.Send("exposeBinding", map[string]interface{}{
+		"name": name,
+	})
+	if err != nil {
+		p.bindingsMu.Lock()
+		delete(p.bindings, name)
+		p.bindingsMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ExposeFunction is ExposeBinding without the source of the call.
+func (p *Page) ExposeFunction(name string, binding ExposedFunction) error {
+	return p.ExposeBinding(name, func(source *BindingSource, args ...interface{}) interface{} {
+		return binding(args...)
+	})
+}
+
+func (p *Page) hasBinding(name string) bool {
+	p.bindingsMu.Lock()
+	defer p.bindingsMu.Unlock()
+	_, ok := p.bindings[name]
+	return ok
+}
+
+// AddInitScript adds a script which is evaluated in every frame of the page
+// before any of their scripts, see BrowserContext.AddInitScript.
+func (b *Page) AddInitScript(options BrowserContextAddInitScriptOptions) error {
+	source, err := getInitScriptSource(options)
+	if err != nil {
+		return err
 	}
-	_, err := b.channel.Send("addInitScript", map[string]interface{}{
+	_, err = b.channel.Send("addInitScript", map[string]interface{}{
 		"source": source,
 	})
 	return err
@@ -457,14 +652,18 @@ func (b *Page) AddInitScript(options BrowserContextAddInitScriptOptions) error {
 
 func newPage(parent *ChannelOwner, objectType string, guid string, initializer map[string]interface{}) *Page {
 	bt := &Page{
-		mainFrame: fromChannel(initializer["mainFrame"]).(*Frame),
-		workers:   make([]*Worker, 0),
-		routes:    make([]*routeHandlerEntry, 0),
-		viewportSize: ViewportSize{
-			Height: int(initializer["viewportSize"].(map[string]interface{})["height"].(float64)),
-			Width:  int(initializer["viewportSize"].(map[string]interface{})["width"].(float64)),
-		},
+		mainFrame:       fromChannel(initializer["mainFrame"]).(*Frame),
+		workers:         make([]*Worker, 0),
+		routes:          make([]*routeHandlerEntry, 0),
 		timeoutSettings: newTimeoutSettings(nil),
+		bindings:        make(map[string]BindingCallFunction),
+	}
+	// pages of a context with NoViewport have none
+	if viewportSize, ok := initializer["viewportSize"].(map[string]interface{}); ok {
+		bt.viewportSize = ViewportSize{
+			Height: int(viewportSize["height"].(float64)),
+			Width:  int(viewportSize["width"].(float64)),
+		}
 	}
 	bt.frames = []*Frame{bt.mainFrame}
 	bt.mainFrame.page = bt
@@ -478,12 +677,34 @@ func newPage(parent *ChannelOwner, objectType string, guid string, initializer m
 	bt.channel.On("console", func(ev map[string]interface{}) {
 		bt.Emit("console", fromChannel(ev["message"]))
 	})
+	bt.channel.On("videoStarted", func(ev map[string]interface{}) {
+		bt.getVideo().setRelativePath(ev["relativePath"].(string))
+	})
+	bt.channel.On("bindingCall", func(params map[string]interface{}) {
+		bindingCall := fromChannel(params["binding"]).(*BindingCall)
+		bt.bindingsMu.Lock()
+		binding := bt.bindings[bindingCall.initializer["name"].(string)]
+		bt.bindingsMu.Unlock()
+		if binding != nil {
+			go bindingCall.call(binding)
+			return
+		}
+		if bt.browserContext != nil {
+			bt.browserContext.onBinding(bindingCall)
+		}
+	})
 	bt.channel.On("crash", func() {
 		bt.Emit("crash")
 	})
 	bt.channel.On("dialog", func(ev map[string]interface{}) {
 		go func() {
-			bt.Emit("dialog", fromChannel(ev["dialog"]))
+			dialog := fromChannel(ev["dialog"]).(*Dialog)
+			// the dialog would block the page without a handler
+			if bt.Emit("dialog", dialog) == 0 {
+				if err := dialog.Dismiss(); err != nil {
+					log.Printf("could not dismiss dialog: %v", err)
+				}
+			}
 		}()
 	})
 	bt.channel.On("domcontentloaded", func() {
@@ -536,14 +757,28 @@ func newPage(parent *ChannelOwner, objectType string, guid string, initializer m
 		route := fromChannel(ev["route"]).(*Route)
 		request := fromChannel(ev["request"]).(*Request)
 		go func() {
+			// the handler is called without holding routesMu, so that it
+			// can call Unroute
+			var handler routeHandler
 			bt.routesMu.Lock()
 			for _, handlerEntry := range bt.routes {
 				if handlerEntry.matcher.Match(request.URL()) {
-					handlerEntry.handler(route, request)
+					handler = handlerEntry.handler
 					break
 				}
 			}
 			bt.routesMu.Unlock()
+			if handler != nil {
+				handler(route, request)
+				return
+			}
+			// the routes of the context apply to the requests which the
+			// routes of the page do not handle
+			if bt.browserContext != nil {
+				bt.browserContext.onRoute(route, request)
+			} else if err := route.Continue(); err != nil {
+				log.Printf("could not continue route: %v", err)
+			}
 		}()
 	})
 	bt.channel.On("worker", func(ev map[string]interface{}) {
@@ -574,14 +809,30 @@ func (p *Page) SetInputFiles(selector string, files []InputFile, options ...Fram
 	return p.mainFrame.SetInputFiles(selector, files, options...)
 }
 
+// SetInputFilesContext is like SetInputFiles but stops waiting once ctx
+// is done.
+func (p *Page) SetInputFilesContext(ctx context.Context, selector string, files []InputFile, options ...FrameSetInputFilesOptions) error {
+	return p.mainFrame.SetInputFilesContext(ctx, selector, files, options...)
+}
+
 func (p *Page) Check(selector string, options ...FrameCheckOptions) error {
 	return p.mainFrame.Check(selector, options...)
 }
 
+// CheckContext is like Check but stops waiting once ctx is done.
+func (p *Page) CheckContext(ctx context.Context, selector string, options ...FrameCheckOptions) error {
+	return p.mainFrame.CheckContext(ctx, selector, options...)
+}
+
 func (p *Page) Uncheck(selector string, options ...FrameUncheckOptions) error {
 	return p.mainFrame.Uncheck(selector, options...)
 }
 
+// UncheckContext is like Uncheck but stops waiting once ctx is done.
+func (p *Page) UncheckContext(ctx context.Context, selector string, options ...FrameUncheckOptions) error {
+	return p.mainFrame.UncheckContext(ctx, selector, options...)
+}
+
 func (p *Page) WaitForTimeout(timeout int) {
 	p.mainFrame.WaitForTimeout(timeout)
 }
@@ -594,10 +845,20 @@ func (p *Page) DblClick(expression string, options ...FrameDblclickOptions) erro
 	return p.mainFrame.DblClick(expression, options...)
 }
 
+// DblClickContext is like DblClick but stops waiting once ctx is done.
+func (p *Page) DblClickContext(ctx context.Context, expression string, options ...FrameDblclickOptions) error {
+	return p.mainFrame.DblClickContext(ctx, expression, options...)
+}
+
 func (p *Page) Focus(expression string, options ...FrameFocusOptions) error {
 	return p.mainFrame.Focus(expression, options...)
 }
 
+// FocusContext is like Focus but stops waiting once ctx is done.
+func (p *Page) FocusContext(ctx context.Context, expression string, options ...FrameFocusOptions) error {
+	return p.mainFrame.FocusContext(ctx, expression, options...)
+}
+
 func (p *Page) TextContent(selector string, options ...FrameTextContentOptions) (string, error) {
 	return p.mainFrame.TextContent(selector, options...)
 }
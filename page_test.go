@@ -500,6 +500,7 @@ func TestPageExpectSelectorTimeout(t *testing.T) {
 		Timeout: Int(500),
 	})).(*TimeoutError)
 	require.Contains(t, timeoutError.Message, "Timeout 500ms exceeded.")
+	require.True(t, errors.Is(timeoutError, ErrTimeout))
 }
 
 func TestPageType(t *testing.T) {
@@ -685,6 +686,30 @@ func TestPageEmulateMedia(t *testing.T) {
 	helper.utils.AssertEval(t, helper.Page, "matchMedia('print').matches", false)
 }
 
+func TestPageEmulateMediaColorScheme(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Page.EmulateMedia(PageEmulateMediaOptions{
+		ColorScheme: "dark",
+	}))
+	helper.utils.AssertEval(t, helper.Page, "matchMedia('(prefers-color-scheme: dark)').matches", true)
+	helper.utils.AssertEval(t, helper.Page, "matchMedia('(prefers-color-scheme: light)').matches", false)
+	require.NoError(t, helper.Page.EmulateMedia(PageEmulateMediaOptions{
+		ColorScheme: "light",
+	}))
+	helper.utils.AssertEval(t, helper.Page, "matchMedia('(prefers-color-scheme: dark)').matches", false)
+	helper.utils.AssertEval(t, helper.Page, "matchMedia('(prefers-color-scheme: light)').matches", true)
+
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		ColorScheme: String("dark"),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	helper.utils.AssertEval(t, page, "matchMedia('(prefers-color-scheme: dark)').matches", true)
+}
+
 func TestPageBringToFront(t *testing.T) {
 	helper := BeforeEach(t)
 	defer helper.AfterEach()
@@ -705,3 +730,48 @@ func TestPageBringToFront(t *testing.T) {
 	require.NoError(t, page1.Close())
 	require.NoError(t, page2.Close())
 }
+
+func TestPageSetExtraHTTPHeadersRotate(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Context.SetExtraHTTPHeaders(map[string]string{
+		"x-variant": "a",
+	}))
+	for _, token := range []string{"first", "second"} {
+		require.NoError(t, helper.Page.SetExtraHTTPHeaders(map[string]string{
+			"authorization": "Bearer " + token,
+		}))
+		request := helper.server.WaitForRequestChan("/empty.html")
+		_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+		require.NoError(t, err)
+		headers := (<-request).Header
+		require.Equal(t, "Bearer "+token, headers.Get("authorization"))
+		require.Equal(t, "a", headers.Get("x-variant"))
+	}
+}
+
+func TestPageExposeFunction(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.NoError(t, helper.Page.ExposeFunction("compute", func(args ...interface{}) interface{} {
+		return args[0].(int) * args[1].(int)
+	}))
+	helper.utils.AssertEval(t, helper.Page, "compute(9, 4)", 36)
+	require.Error(t, helper.Page.ExposeFunction("compute", func(args ...interface{}) interface{} {
+		return nil
+	}))
+}
+
+func TestPageExposeBinding(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	var bindingSource *BindingSource
+	require.NoError(t, helper.Page.ExposeBinding("add", func(source *BindingSource, args ...interface{}) interface{} {
+		bindingSource = source
+		return args[0].(int) + args[1].(int)
+	}))
+	helper.utils.AssertEval(t, helper.Page, "add(5, 6)", 11)
+	require.Equal(t, helper.Context, bindingSource.Context)
+	require.Equal(t, helper.Page, bindingSource.Page)
+	require.Equal(t, helper.Page.MainFrame(), bindingSource.Frame)
+}
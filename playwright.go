@@ -1,6 +1,12 @@
 // Package playwright is a library to automate Chromium, Firefox and WebKit with
 // a single API. Playwright is built to enable cross-browser web automation that
 // is ever-green, capable, reliable and fast.
+//
+// Methods which send a request to the driver and wait for its reply, like
+// Page.Goto, Browser.NewContext or BrowserType.Launch, have a Context variant
+// like Page.GotoContext. Cancelling its ctx only stops waiting for the reply:
+// the driver keeps running the operation, e.g. the page still navigates. Use
+// the Timeout options to bound the operation itself.
 package playwright
 
 type DeviceDescriptor struct {
@@ -17,10 +23,49 @@ type Playwright struct {
 	Chromium *BrowserType
 	Firefox  *BrowserType
 	WebKit   *BrowserType
+	// Electron launches Electron applications, it is nil if the driver does
+	// not support them.
+	Electron *Electron
 	Devices  map[string]*DeviceDescriptor
 }
 
+// SetDefaultTimeout changes the default maximum time in milliseconds for all
+// methods accepting a timeout option in browser contexts which are created
+// afterwards. BrowserContext.SetDefaultTimeout and Page.SetDefaultTimeout
+// take priority over it.
+func (p *Playwright) SetDefaultTimeout(timeout int) {
+	p.connection.timeoutSettings.SetTimeout(timeout)
+}
+
+// SetDefaultNavigationTimeout changes the default maximum navigation time in
+// milliseconds in browser contexts which are created afterwards.
+// BrowserContext.SetDefaultNavigationTimeout and
+// Page.SetDefaultNavigationTimeout take priority over it.
+func (p *Playwright) SetDefaultNavigationTimeout(timeout int) {
+	p.connection.timeoutSettings.SetNavigationTimeout(timeout)
+}
+
+// OnClose registers a handler which is called once the connection to the
+// driver terminated, with a nil error after Stop and otherwise with the reason,
+// e.g. ErrDriverCrashed. Services can use it to mark themselves unhealthy.
+func (p *Playwright) OnClose(handler func(err error)) {
+	p.connection.OnClose(handler)
+}
+
+// Connection returns the connection to the driver, e.g. to register an
+// OnMessage hook.
+func (p *Playwright) Connection() *Connection {
+	return p.connection
+}
+
+// Stop closes all browsers which were launched by this instance, shuts down
+// the connection and waits until the driver process has exited.
 func (p *Playwright) Stop() error {
+	for _, browserType := range []*BrowserType{p.Chromium, p.Firefox, p.WebKit} {
+		if err := browserType.closeBrowsers(); err != nil {
+			return err
+		}
+	}
 	return p.connection.Stop()
 }
 
@@ -32,6 +77,9 @@ func newPlaywright(parent *ChannelOwner, objectType string, guid string, initial
 		WebKit:   fromChannel(initializer["webkit"]).(*BrowserType),
 		Devices:  make(map[string]*DeviceDescriptor),
 	}
+	if electron, ok := initializer["electron"]; ok && electron != nil {
+		pw.Electron, _ = fromChannel(electron).(*Electron)
+	}
 	for _, dd := range initializer["deviceDescriptors"].([]interface{}) {
 		entry := dd.(map[string]interface{})
 		pw.Devices[entry["name"].(string)] = &DeviceDescriptor{
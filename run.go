@@ -1,117 +1,784 @@
 package playwright
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+	"time"
 )
 
-func getDriverURL() (string, string) {
+const driverVersion = "1.4.0"
+
+// getDriverName returns the name of the driver artifact for the platform,
+// driverVersion is only published for amd64.
+func getDriverName(goos, goarch string) (string, error) {
+	switch goos + "/" + goarch {
+	case "windows/amd64":
+		return "playwright-driver-win.exe", nil
+	case "darwin/amd64":
+		return "playwright-driver-macos", nil
+	case "linux/amd64":
+		return "playwright-driver-linux", nil
+	}
+	return "", fmt.Errorf("unsupported platform %s/%s: the Playwright driver %s is available for windows/amd64, darwin/amd64 and linux/amd64", goos, goarch, driverVersion)
+}
+
+// isMuslWithoutGlibcCompat reports whether the system uses the musl libc (like
+// Alpine Linux) without a glibc compatibility layer, on which the driver crashes.
+func isMuslWithoutGlibcCompat() bool {
+	musl, _ := filepath.Glob("/lib/ld-musl-*.so.1")
+	if len(musl) == 0 {
+		return false
+	}
+	glibc, _ := filepath.Glob("/lib*/ld-linux*.so.*")
+	return len(glibc) == 0
+}
+
+func getDriverURL() (string, string, error) {
 	const baseURL = "https://storage.googleapis.com/mxschmitt-public-files/"
-	const version = "playwright-driver-1.4.0"
-	driverName := ""
-	switch runtime.GOOS {
-	case "windows":
-		driverName = "playwright-driver-win.exe"
-	case "darwin":
-		driverName = "playwright-driver-macos"
-	case "linux":
-		driverName = "playwright-driver-linux"
+	version := "playwright-driver-" + driverVersion
+	driverName, err := getDriverName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", "", err
+	}
+	if runtime.GOOS == "linux" && isMuslWithoutGlibcCompat() {
+		return "", "", errors.New("unsupported platform: the Playwright driver and browsers require glibc but this system uses musl (e.g. Alpine Linux). Use a glibc based image like Debian or Ubuntu or install a compatibility layer (apk add gcompat)")
 	}
-	return fmt.Sprintf("%s%s/%s", baseURL, version, driverName), driverName
+	return fmt.Sprintf("%s%s/%s", baseURL, version, driverName), driverName, nil
 }
 
-func installPlaywright() (string, error) {
-	driverURL, driverName := getDriverURL()
+// getDriverFolder returns the folder where the driver gets stored. It defaults
+// to the user cache directory, so multiple projects share one installation, and
+// falls back to the current working directory.
+func getDriverFolder(options *InstallOptions) (string, error) {
+	if options != nil && options.DriverDirectory != "" {
+		return options.DriverDirectory, nil
+	}
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "ms-playwright-go"), nil
+	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("could not get cwd: %w", err)
 	}
-	driverFolder := filepath.Join(cwd, ".ms-playwright")
-	if _, err = os.Stat(driverFolder); os.IsNotExist(err) {
-		if err := os.Mkdir(driverFolder, 0777); err != nil {
-			return "", fmt.Errorf("could not create driver folder :%w", err)
+	return filepath.Join(cwd, ".ms-playwright"), nil
+}
+
+const browsersPathEnv = "PLAYWRIGHT_BROWSERS_PATH"
+
+// getBrowsersFolder returns the location where the driver stores the
+// downloaded browsers, see the registry of the Playwright driver.
+func getBrowsersFolder(options *InstallOptions) (string, error) {
+	if options != nil && options.BrowsersPath != "" {
+		return options.BrowsersPath, nil
+	}
+	if browsersPath := os.Getenv(browsersPathEnv); browsersPath == "0" {
+		return "", fmt.Errorf("%s=0 is not supported, the browsers are stored inside of the driver package", browsersPathEnv)
+	} else if browsersPath != "" {
+		return browsersPath, nil
+	}
+	switch runtime.GOOS {
+	case "windows":
+		localAppData := os.Getenv("LOCALAPPDATA")
+		if localAppData == "" {
+			return "", fmt.Errorf("could not get LOCALAPPDATA")
 		}
+		return filepath.Join(localAppData, "ms-playwright"), nil
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not get home directory: %w", err)
+		}
+		return filepath.Join(home, "Library", "Caches", "ms-playwright"), nil
+	default:
+		cacheHome := os.Getenv("XDG_CACHE_HOME")
+		if cacheHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("could not get home directory: %w", err)
+			}
+			cacheHome = filepath.Join(home, ".cache")
+		}
+		return filepath.Join(cacheHome, "ms-playwright"), nil
 	}
-	driverPath := filepath.Join(driverFolder, driverName)
-	if _, err := os.Stat(driverPath); err == nil {
-		return driverPath, nil
+}
+
+type statusCodeError struct {
+	statusCode int
+	status     string
+}
+
+func (e *statusCodeError) Error() string {
+	return fmt.Sprintf("error: got non 2xx status code: %d (%s)", e.statusCode, e.status)
+}
+
+// retryWithBackoff calls f until it succeeds or the retries are exhausted. The
+// delay between the attempts doubles every time and gets a random jitter of up
+// to 50% added. Client errors (4xx) are not retried.
+func retryWithBackoff(retries int, delay time.Duration, f func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		var statusErr *statusCodeError
+		if errors.As(err, &statusErr) && statusErr.statusCode >= 400 && statusErr.statusCode < 500 {
+			return err
+		}
+		if attempt >= retries {
+			return err
+		}
+		wait := delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+		log.Printf("Attempt %d failed, retrying in %s: %v", attempt+1, wait, err)
+		time.Sleep(wait)
+		delay *= 2
+	}
+}
+
+// downloadDriver downloads the driver into a .partial file next to driverPath
+// and moves it into place once it's complete. If a .partial file of a previous
+// attempt exists, the download gets resumed by a HTTP range request.
+func downloadDriver(driverURL, driverPath string) error {
+	partialPath := driverPath + ".partial"
+	var offset int64
+	if stats, err := os.Stat(partialPath); err == nil {
+		offset = stats.Size()
+	}
+	req, err := http.NewRequest(http.MethodGet, driverURL, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
-	log.Println("Downloading driver...")
-	resp, err := http.Get(driverURL)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("could not download driver: %w", err)
+		return fmt.Errorf("could not download driver: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("error: got non 2xx status code: %d (%s)", resp.StatusCode, resp.Status)
+	defer resp.Body.Close()
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		log.Printf("Resuming driver download at %d bytes", offset)
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is either complete or bogus, start from scratch.
+		os.Remove(partialPath)
+		return fmt.Errorf("could not resume driver download: %s", resp.Status)
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+	default:
+		return &statusCodeError{statusCode: resp.StatusCode, status: resp.Status}
 	}
-	outFile, err := os.Create(driverPath)
+	outFile, err := os.OpenFile(partialPath, flags, 0777)
 	if err != nil {
-		return "", fmt.Errorf("could not create driver: %w", err)
+		return fmt.Errorf("could not create driver: %w", err)
 	}
 	if _, err = io.Copy(outFile, resp.Body); err != nil {
-		return "", fmt.Errorf("could not copy response body to file: %w", err)
+		outFile.Close()
+		return fmt.Errorf("could not copy response body to file: %w", err)
 	}
 	if err := outFile.Close(); err != nil {
-		return "", fmt.Errorf("could not close file (driver): %w", err)
+		return fmt.Errorf("could not close file (driver): %w", err)
 	}
+	if err := os.Rename(partialPath, driverPath); err != nil {
+		return fmt.Errorf("could not move driver into place: %w", err)
+	}
+	return nil
+}
 
-	if runtime.GOOS != "windows" {
-		stats, err := os.Stat(driverPath)
-		if err != nil {
-			return "", fmt.Errorf("could not stat driver: %w", err)
+func makeExecutable(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	stats, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("could not stat driver: %w", err)
+	}
+	if err := os.Chmod(path, stats.Mode()|0x40); err != nil {
+		return fmt.Errorf("could not set permissions: %w", err)
+	}
+	return nil
+}
+
+func installDriver(options *InstallOptions) (driverPath string, downloaded bool, err error) {
+	driverURL, driverName, err := getDriverURL()
+	if err != nil {
+		return "", false, err
+	}
+	driverFolder, err := getDriverFolder(options)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err = os.Stat(driverFolder); os.IsNotExist(err) {
+		if err := os.MkdirAll(driverFolder, 0777); err != nil {
+			return "", false, fmt.Errorf("could not create driver folder :%w", err)
+		}
+	}
+	driverPath = filepath.Join(driverFolder, driverName)
+	if _, err := os.Stat(driverPath); err == nil {
+		installedVersion, err := getDriverVersion(driverPath)
+		switch {
+		case err != nil:
+			options.logf("Driver is not usable (%v), downloading it again...", err)
+		case installedVersion == driverVersion:
+			return driverPath, false, nil
+		default:
+			options.logf("Driver %s is outdated, updating to %s...", installedVersion, driverVersion)
 		}
-		if err := os.Chmod(driverPath, stats.Mode()|0x40); err != nil {
-			return "", fmt.Errorf("could not set permissions: %w", err)
+		if err := os.Remove(driverPath); err != nil {
+			return "", false, fmt.Errorf("could not remove existing driver: %w", err)
 		}
 	}
-	log.Println("Downloaded driver successfully")
+	options.logf("Downloading driver...")
+	if err := retryWithBackoff(options.downloadRetries(), options.downloadRetryDelay(), func() error {
+		if err := downloadDriver(driverURL, driverPath); err != nil {
+			return err
+		}
+		if err := makeExecutable(driverPath); err != nil {
+			return err
+		}
+		// Make sure that the download was not truncated or corrupted.
+		if _, err := getDriverVersion(driverPath); err != nil {
+			os.Remove(driverPath)
+			return fmt.Errorf("downloaded driver is not usable: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return "", false, err
+	}
+	options.logf("Downloaded driver successfully")
+	return driverPath, true, nil
+}
+
+// lockInstallation prevents that multiple processes install the driver or the
+// browsers at the same time, e.g. parallel test binaries, and corrupt them.
+func lockInstallation(options *InstallOptions) (*fileLock, error) {
+	driverFolder, err := getDriverFolder(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(driverFolder, 0777); err != nil {
+		return nil, fmt.Errorf("could not create driver folder :%w", err)
+	}
+	return lockFile(filepath.Join(driverFolder, ".install.lock"))
+}
 
-	log.Println("Downloading browsers...")
-	if err := installBrowsers(driverPath); err != nil {
+func installPlaywright(options *RunOptions) (string, error) {
+	installOptions := options.installOptions()
+	lock, err := lockInstallation(installOptions)
+	if err != nil {
+		return "", fmt.Errorf("could not lock installation: %w", err)
+	}
+	defer lock.Unlock()
+	driverPath, _, err := installDriver(installOptions)
+	if err != nil {
+		return "", err
+	}
+	// Without explicitly requested browsers they get installed lazily on
+	// their first launch, see installBrowserOnLaunch. Requested ones are
+	// installed even with an existing driver, the driver skips the browsers
+	// which are installed already.
+	if options.SkipInstallBrowsers || len(options.Browsers) == 0 {
+		return driverPath, nil
+	}
+	installOptions.logf("Downloading browsers...")
+	if err := installBrowsers(driverPath, installOptions); err != nil {
 		return "", fmt.Errorf("could not install browsers: %w", err)
 	}
-	log.Println("Downloaded browsers successfully")
+	installOptions.logf("Downloaded browsers successfully")
 	return driverPath, nil
 }
 
-func installBrowsers(driverPath string) error {
-	cmd := exec.Command(driverPath, "--install")
-	cmd.Stdout = os.Stdout
+// installedBrowsersFile lists the browser folders which were installed by
+// this driver, one path per line. Uninstall removes only them, the browsers
+// folder is shared with the other Playwright languages.
+const installedBrowsersFile = ".installed-browsers"
+
+// listBrowsers returns the paths of the entries of the browsers folder.
+func listBrowsers(browsersFolder string) map[string]bool {
+	browsers := map[string]bool{}
+	entries, err := ioutil.ReadDir(browsersFolder)
+	if err != nil {
+		return browsers
+	}
+	for _, entry := range entries {
+		browsers[filepath.Join(browsersFolder, entry.Name())] = true
+	}
+	return browsers
+}
+
+// recordInstalledBrowsers appends the browsers which appeared since before
+// to the installedBrowsersFile of the driver folder.
+func recordInstalledBrowsers(options *InstallOptions, browsersFolder string, before map[string]bool) error {
+	driverFolder, err := getDriverFolder(options)
+	if err != nil {
+		return err
+	}
+	installed := make([]string, 0)
+	for browser := range listBrowsers(browsersFolder) {
+		if !before[browser] {
+			installed = append(installed, browser)
+		}
+	}
+	if len(installed) == 0 {
+		return nil
+	}
+	sort.Strings(installed)
+	file, err := os.OpenFile(filepath.Join(driverFolder, installedBrowsersFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not record installed browsers: %w", err)
+	}
+	if _, err := file.WriteString(strings.Join(installed, "\n") + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("could not record installed browsers: %w", err)
+	}
+	return file.Close()
+}
+
+func installBrowsers(driverPath string, options *InstallOptions) error {
+	browsersFolder, err := getBrowsersFolder(options)
+	if err != nil {
+		return err
+	}
+	before := listBrowsers(browsersFolder)
+	installErr := installBrowsersWithDeps(driverPath, options)
+	// record the browsers of a failed installation as well, they are partial
+	if err := recordInstalledBrowsers(options, browsersFolder, before); err != nil && installErr == nil {
+		return err
+	}
+	return installErr
+}
+
+func installBrowsersWithDeps(driverPath string, options *InstallOptions) error {
+	if options.WithDeps {
+		if err := runDriverCommand(driverPath, "--install-deps", options); err != nil {
+			return fmt.Errorf("could not install dependencies: %w", err)
+		}
+	}
+	return retryWithBackoff(options.downloadRetries(), options.downloadRetryDelay(), func() error {
+		return runDriverCommand(driverPath, "--install", options)
+	})
+}
+
+// driverEnv returns the environment of the driver process, which uses
+// PLAYWRIGHT_BROWSERS_PATH when installing and launching browsers.
+func driverEnv(browsersPath string) []string {
+	if browsersPath == "" {
+		return nil
+	}
+	return append(os.Environ(), browsersPathEnv+"="+browsersPath)
+}
+
+func runDriverCommand(driverPath string, command string, options *InstallOptions) error {
+	cmd := exec.Command(driverPath, append([]string{command}, options.Browsers...)...)
+	cmd.Env = driverEnv(options.BrowsersPath)
 	cmd.Stderr = os.Stderr
+	if options.Events != nil {
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return fmt.Errorf("could not get stdout pipe: %w", err)
+		}
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("could not start driver: %w", err)
+		}
+		emitInstallEvents(stdout, options.Events)
+		return cmd.Wait()
+	}
+	if options.verbose() {
+		cmd.Stdout = os.Stdout
+	}
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("could not start driver: %w", err)
 	}
-	if err := cmd.Wait(); err != nil {
-		return err
+	return cmd.Wait()
+}
+
+// InstallOptions are the options which can be passed to Install.
+type InstallOptions struct {
+	// Browsers which should get installed, e.g. "chromium". All of them get
+	// installed if none are given.
+	Browsers []string
+	// BrowsersPath is the folder where the browsers get stored. Defaults to
+	// the PLAYWRIGHT_BROWSERS_PATH environment variable, which is shared with
+	// the other Playwright languages, or the driver's default cache folder.
+	BrowsersPath string
+	// DriverDirectory is the folder where the driver gets stored. Defaults to
+	// ms-playwright-go in the user cache directory, use ".ms-playwright" to
+	// keep it in the current working directory.
+	DriverDirectory string
+	// WithDeps installs the system dependencies of the browsers as well, e.g.
+	// the shared libraries on Linux. This usually requires root privileges.
+	WithDeps bool
+	// Verbose logs the progress of the installation. Defaults to true.
+	Verbose *bool
+	// Events receives the progress of the browser downloads instead of
+	// printing the driver output. The channel needs to be drained while
+	// installing and does not get closed.
+	Events chan<- InstallEvent
+	// DownloadRetries is the amount of retries if downloading the driver or
+	// the browsers fails. Defaults to 3.
+	DownloadRetries *int
+	// DownloadRetryDelay is the delay before the first retry, it doubles with
+	// every further attempt. Defaults to 1 second.
+	DownloadRetryDelay *time.Duration
+}
+
+const (
+	defaultDownloadRetries    = 3
+	defaultDownloadRetryDelay = time.Second
+)
+
+func getInstallOptions(options []*InstallOptions) *InstallOptions {
+	if len(options) == 1 && options[0] != nil {
+		return options[0]
 	}
-	return nil
+	return &InstallOptions{}
+}
+
+func (o *InstallOptions) verbose() bool {
+	return o == nil || o.Verbose == nil || *o.Verbose
+}
+
+func (o *InstallOptions) logf(format string, v ...interface{}) {
+	if o.verbose() {
+		log.Printf(format, v...)
+	}
+}
+
+func (o *InstallOptions) downloadRetries() int {
+	if o == nil || o.DownloadRetries == nil {
+		return defaultDownloadRetries
+	}
+	return *o.DownloadRetries
+}
+
+func (o *InstallOptions) downloadRetryDelay() time.Duration {
+	if o == nil || o.DownloadRetryDelay == nil {
+		return defaultDownloadRetryDelay
+	}
+	return *o.DownloadRetryDelay
 }
 
 // Install does download the driver and the browsers. If not called manually
 // before playwright.Run() it will get executed there and might take a few seconds
 // to download the Playwright suite.
-func Install() error {
-	_, err := installPlaywright()
+func Install(options ...*InstallOptions) error {
+	option := getInstallOptions(options)
+	lock, err := lockInstallation(option)
+	if err != nil {
+		return fmt.Errorf("could not lock installation: %w", err)
+	}
+	defer lock.Unlock()
+	driverPath, _, err := installDriver(option)
 	if err != nil {
 		return fmt.Errorf("could not install driver: %w", err)
 	}
+	option.logf("Downloading browsers...")
+	if err := installBrowsers(driverPath, option); err != nil {
+		return fmt.Errorf("could not install browsers: %w", err)
+	}
+	option.logf("Downloaded browsers successfully")
+	return nil
+}
+
+// DriverVersion returns the version of the Playwright driver which is used
+// by this package.
+func DriverVersion() string {
+	return driverVersion
+}
+
+// InstalledDriverPath returns the location of the downloaded driver or an
+// empty string if it was not installed yet.
+func InstalledDriverPath(options ...*InstallOptions) (string, error) {
+	driverFolder, err := getDriverFolder(getInstallOptions(options))
+	if err != nil {
+		return "", err
+	}
+	_, driverName, err := getDriverURL()
+	if err != nil {
+		return "", err
+	}
+	driverPath := filepath.Join(driverFolder, driverName)
+	if _, err := os.Stat(driverPath); os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("could not stat driver: %w", err)
+	}
+	return driverPath, nil
+}
+
+// InstalledDriverVersion returns the version of the downloaded driver by
+// running it with --version or an empty string if it was not installed yet.
+func InstalledDriverVersion(options ...*InstallOptions) (string, error) {
+	driverPath, err := InstalledDriverPath(options...)
+	if err != nil || driverPath == "" {
+		return "", err
+	}
+	return getDriverVersion(driverPath)
+}
+
+// IsDriverUpToDate reports whether the downloaded driver has the version
+// which this package targets. Install and Run update an outdated driver.
+func IsDriverUpToDate(options ...*InstallOptions) (bool, error) {
+	installedVersion, err := InstalledDriverVersion(options...)
+	if err != nil {
+		return false, err
+	}
+	return installedVersion == driverVersion, nil
+}
+
+func getDriverVersion(driverPath string) (string, error) {
+	output, err := exec.Command(driverPath, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("could not get driver version: %w", err)
+	}
+	// The output looks like "Version 1.4.0"
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("could not parse driver version: %q", output)
+	}
+	return fields[len(fields)-1], nil
+}
+
+// Uninstall removes the downloaded driver and the browsers which were installed
+// by it. The browsers of the other Playwright languages in the shared browsers
+// folder are kept. The next call of Install or Run will download them again.
+func Uninstall(options ...*InstallOptions) error {
+	option := getInstallOptions(options)
+	driverFolder, err := getDriverFolder(option)
+	if err != nil {
+		return fmt.Errorf("could not uninstall driver: %w", err)
+	}
+	if _, err := os.Stat(driverFolder); os.IsNotExist(err) {
+		return nil
+	}
+	lock, err := lockInstallation(option)
+	if err != nil {
+		return fmt.Errorf("could not lock installation: %w", err)
+	}
+	lockPath := filepath.Join(driverFolder, ".install.lock")
+	defer func() {
+		// the lock file goes last, together with the then empty driver folder
+		lock.Unlock()
+		os.Remove(lockPath)
+		os.Remove(driverFolder)
+	}()
+	browsersFolder, err := getBrowsersFolder(option)
+	if err != nil {
+		return fmt.Errorf("could not uninstall browsers: %w", err)
+	}
+	recordPath := filepath.Join(driverFolder, installedBrowsersFile)
+	if record, err := ioutil.ReadFile(recordPath); err == nil {
+		for _, browser := range strings.Split(string(record), "\n") {
+			// only remove what is still inside of the browsers folder
+			if browser == "" || filepath.Dir(browser) != filepath.Clean(browsersFolder) {
+				continue
+			}
+			if err := os.RemoveAll(browser); err != nil {
+				return fmt.Errorf("could not remove browser: %w", err)
+			}
+		}
+		if err := os.Remove(recordPath); err != nil {
+			return fmt.Errorf("could not remove installed browsers record: %w", err)
+		}
+	}
+	_, driverName, err := getDriverURL()
+	if err != nil {
+		return fmt.Errorf("could not uninstall driver: %w", err)
+	}
+	driverPath := filepath.Join(driverFolder, driverName)
+	if err := os.Remove(driverPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove driver: %w", err)
+	}
+	os.Remove(driverPath + ".partial")
 	return nil
 }
 
-func Run() (*Playwright, error) {
-	driverPath, err := installPlaywright()
+// RunOptions are the options which can be passed to Run.
+type RunOptions struct {
+	// DriverDirectory is the folder where the driver gets stored, see
+	// InstallOptions.DriverDirectory.
+	DriverDirectory string
+	// Browsers which should get installed by Run. If none are given, each
+	// browser gets installed on the first launch of its BrowserType.
+	Browsers []string
+	// BrowsersPath is the folder where the browsers are stored, see
+	// InstallOptions.BrowsersPath.
+	BrowsersPath string
+	// SkipInstallBrowsers only installs the driver, the browsers need to be
+	// installed already.
+	SkipInstallBrowsers bool
+	// Verbose logs the progress of the installation. Defaults to true.
+	Verbose *bool
+	// Stderr receives the stderr output of the driver, every line is prefixed
+	// with the PID of the driver. Defaults to os.Stderr.
+	Stderr io.Writer
+	// Logger receives the stderr output of the driver line by line instead of
+	// Stderr, e.g. to route it into the logging of the application.
+	Logger func(pid int, line string)
+	// HeartbeatInterval enables a periodic ping of the driver. If the driver
+	// does not reply within the interval, all calls fail with
+	// ErrDriverUnresponsive and the driver gets killed. Disabled by default.
+	HeartbeatInterval time.Duration
+	// MaxInFlightBytes limits the size of the received messages which were not
+	// processed yet, e.g. big screenshots which were not picked up by their
+	// caller. Reading from the driver pauses until enough of them have been
+	// processed. Unlimited by default.
+	MaxInFlightBytes int
+	// IsolateSignals runs the driver in its own process group, so that signals
+	// to the application, e.g. Ctrl+C in a terminal, do not stop the driver
+	// and its browsers. Together with the HandleSIGINT, HandleSIGTERM and
+	// HandleSIGHUP launch options set to false, the application decides when
+	// the browsers are closed, e.g. after draining its work on shutdown.
+	IsolateSignals bool
+}
+
+func getRunOptions(options []*RunOptions) *RunOptions {
+	if len(options) == 1 && options[0] != nil {
+		return options[0]
+	}
+	return &RunOptions{}
+}
+
+func (o *RunOptions) installOptions() *InstallOptions {
+	return &InstallOptions{
+		Browsers:        o.Browsers,
+		BrowsersPath:    o.BrowsersPath,
+		DriverDirectory: o.DriverDirectory,
+		Verbose:         o.Verbose,
+	}
+}
+
+func (o *RunOptions) logger() func(pid int, line string) {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	stderr := o.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return func(pid int, line string) {
+		fmt.Fprintf(stderr, "playwright driver[%d]: %s\n", pid, line)
+	}
+}
+
+// logDriverStderr passes the stderr output of the driver line by line to
+// logger until the driver closes it.
+func logDriverStderr(stderr io.Reader, pid int, logger func(pid int, line string)) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		logger(pid, scanner.Text())
+	}
+	// keep draining after an overlong line, the driver must not block on it
+	_, _ = io.Copy(ioutil.Discard, stderr)
+}
+
+// Run installs the driver and the browsers if needed and starts the driver.
+// Every call starts its own driver process with its own connection, so several
+// independent instances can be used at the same time, e.g. in parallel tests.
+// Concurrent installations into the same folders wait for each other.
+func Run(options ...*RunOptions) (*Playwright, error) {
+	option := getRunOptions(options)
+	driverPath, err := installPlaywright(option)
 	if err != nil {
 		return nil, fmt.Errorf("could not install driver: %w", err)
 	}
+	pw, err := runDriver(driverPath, option)
+	if err != nil {
+		return nil, err
+	}
+	if !option.SkipInstallBrowsers && len(option.Browsers) == 0 {
+		for _, browserType := range []*BrowserType{pw.Chromium, pw.Firefox, pw.WebKit} {
+			browserType.installBrowser = installBrowserOnLaunch(driverPath, option.installOptions())
+		}
+	}
+	return pw, nil
+}
+
+func installBrowserOnLaunch(driverPath string, options *InstallOptions) func(name string) error {
+	return func(name string) error {
+		lock, err := lockInstallation(options)
+		if err != nil {
+			return fmt.Errorf("could not lock installation: %w", err)
+		}
+		defer lock.Unlock()
+		browserOptions := *options
+		browserOptions.Browsers = []string{name}
+		if err := installBrowsers(driverPath, &browserOptions); err != nil {
+			return fmt.Errorf("could not install %s: %w", name, err)
+		}
+		return nil
+	}
+}
+
+// RunWithDriver starts the driver at the given path without installing
+// anything, which is useful for hermetic builds or custom driver builds.
+// The browsers need to be installed already.
+func RunWithDriver(driverPath string, options ...*RunOptions) (*Playwright, error) {
+	if _, err := os.Stat(driverPath); err != nil {
+		return nil, fmt.Errorf("could not find driver: %w", err)
+	}
+	return runDriver(driverPath, getRunOptions(options))
+}
+
+// RunWithTransport speaks the protocol over the given transport instead of
+// starting a driver process, e.g. to reach a driver over TCP or to use an
+// in-process test double. Stop closes the transport.
+func RunWithTransport(transport Transport) (*Playwright, error) {
+	connection := newConnection(transport, func() error {
+		return nil
+	})
+	go func() {
+		connection.transportClosed(connection.Start())
+	}()
+	obj, err := connection.CallOnObjectWithKnownName("Playwright")
+	if err != nil {
+		return nil, fmt.Errorf("could not call object: %w", err)
+	}
+	return obj.(*Playwright), nil
+}
+
+// driverExitTimeout is how long Stop waits for the driver to exit on its own
+// after its stdin was closed before it gets killed.
+const driverExitTimeout = 10 * time.Second
 
+// stopDriverProcess waits until the driver process, whose stdin was closed
+// already, has exited and kills it if it does not exit in time.
+func stopDriverProcess(cmd *exec.Cmd, exited <-chan struct{}) error {
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(driverExitTimeout):
+	}
+	if err := cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("could not kill driver: %w", err)
+	}
+	<-exited
+	return nil
+}
+
+func runDriver(driverPath string, options *RunOptions) (*Playwright, error) {
 	cmd := exec.Command(driverPath, "--run")
-	cmd.Stderr = os.Stderr
+	cmd.Env = driverEnv(options.BrowsersPath)
+	if options.IsolateSignals {
+		isolateSignals(cmd)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("could not get stderr pipe: %w", err)
+	}
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, fmt.Errorf("could not get stdin pipe: %w", err)
@@ -123,15 +790,30 @@ func Run() (*Playwright, error) {
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("could not start driver: %w", err)
 	}
-	connection := newConnection(stdin, stdout, cmd.Process.Kill)
+	stderrDone := make(chan struct{})
 	go func() {
-		if err := connection.Start(); err != nil {
-			log.Fatalf("could not start connection: %v", err)
-		}
+		logDriverStderr(stderr, cmd.Process.Pid, options.logger())
+		close(stderrDone)
+	}()
+	exited := make(chan struct{})
+	connection := newConnection(newPipeTransport(stdin, stdout), func() error {
+		return stopDriverProcess(cmd, exited)
+	})
+	connection.budget = newByteBudget(options.MaxInFlightBytes)
+	go func() {
+		err := connection.Start()
+		<-stderrDone
+		_ = cmd.Wait()
+		close(exited)
+		connection.transportClosed(err)
 	}()
 	obj, err := connection.CallOnObjectWithKnownName("Playwright")
 	if err != nil {
 		return nil, fmt.Errorf("could not call object: %w", err)
 	}
-	return obj.(*Playwright), nil
+	pw := obj.(*Playwright)
+	if options.HeartbeatInterval > 0 {
+		go connection.heartbeat(options.HeartbeatInterval)
+	}
+	return pw, nil
 }
@@ -0,0 +1,213 @@
+package playwright
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryWithBackoff(t *testing.T) {
+	attempts := 0
+	err := retryWithBackoff(3, 1, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+}
+
+func TestRetryWithBackoffExhausted(t *testing.T) {
+	attempts := 0
+	err := retryWithBackoff(2, 1, func() error {
+		attempts++
+		return &statusCodeError{statusCode: http.StatusBadGateway, status: "502 Bad Gateway"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, attempts)
+}
+
+func TestRetryWithBackoffClientError(t *testing.T) {
+	attempts := 0
+	err := retryWithBackoff(3, 1, func() error {
+		attempts++
+		return &statusCodeError{statusCode: http.StatusNotFound, status: "404 Not Found"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestGetDriverName(t *testing.T) {
+	testCases := []struct {
+		goos       string
+		goarch     string
+		driverName string
+	}{
+		{"windows", "amd64", "playwright-driver-win.exe"},
+		{"darwin", "amd64", "playwright-driver-macos"},
+		{"linux", "amd64", "playwright-driver-linux"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.goos+"/"+tc.goarch, func(t *testing.T) {
+			driverName, err := getDriverName(tc.goos, tc.goarch)
+			require.NoError(t, err)
+			require.Equal(t, tc.driverName, driverName)
+		})
+	}
+}
+
+func TestGetDriverNameUnsupportedPlatform(t *testing.T) {
+	for _, platform := range [][2]string{{"linux", "s390x"}, {"linux", "arm64"}, {"darwin", "arm64"}, {"windows", "386"}} {
+		_, err := getDriverName(platform[0], platform[1])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported platform "+platform[0]+"/"+platform[1])
+	}
+}
+
+func TestGetDriverVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	driverPath := filepath.Join(t.TempDir(), "driver")
+	require.NoError(t, ioutil.WriteFile(driverPath, []byte("#!/bin/sh\necho Version 1.4.0\n"), 0755))
+	version, err := getDriverVersion(driverPath)
+	require.NoError(t, err)
+	require.Equal(t, "1.4.0", version)
+
+	require.NoError(t, ioutil.WriteFile(driverPath, []byte{0x7f, 'E', 'L', 'F'}, 0755))
+	_, err = getDriverVersion(driverPath)
+	require.Error(t, err)
+}
+
+func TestDownloadDriverResume(t *testing.T) {
+	content := strings.Repeat("playwright", 1024)
+	var rangeHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader = r.Header.Get("Range")
+		http.ServeContent(w, r, "driver", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+	driverPath := filepath.Join(t.TempDir(), "driver")
+	require.NoError(t, ioutil.WriteFile(driverPath+".partial", []byte(content[:100]), 0644))
+
+	require.NoError(t, downloadDriver(server.URL, driverPath))
+	require.Equal(t, "bytes=100-", rangeHeader)
+	downloaded, err := ioutil.ReadFile(driverPath)
+	require.NoError(t, err)
+	require.Equal(t, content, string(downloaded))
+	_, err = os.Stat(driverPath + ".partial")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestGetDriverFolder(t *testing.T) {
+	driverFolder, err := getDriverFolder(&InstallOptions{DriverDirectory: "foo"})
+	require.NoError(t, err)
+	require.Equal(t, "foo", driverFolder)
+
+	cacheDir, err := os.UserCacheDir()
+	require.NoError(t, err)
+	driverFolder, err = getDriverFolder(nil)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cacheDir, "ms-playwright-go"), driverFolder)
+}
+
+func TestGetBrowsersFolder(t *testing.T) {
+	browsersFolder, err := getBrowsersFolder(&InstallOptions{BrowsersPath: "foo"})
+	require.NoError(t, err)
+	require.Equal(t, "foo", browsersFolder)
+
+	previous, hadPrevious := os.LookupEnv(browsersPathEnv)
+	defer func() {
+		if hadPrevious {
+			os.Setenv(browsersPathEnv, previous)
+		} else {
+			os.Unsetenv(browsersPathEnv)
+		}
+	}()
+	require.NoError(t, os.Setenv(browsersPathEnv, "bar"))
+	browsersFolder, err = getBrowsersFolder(nil)
+	require.NoError(t, err)
+	require.Equal(t, "bar", browsersFolder)
+}
+
+func TestLogDriverStderr(t *testing.T) {
+	lines := []string{}
+	logDriverStderr(strings.NewReader("foo\nbar\n"), 42, func(pid int, line string) {
+		require.Equal(t, 42, pid)
+		lines = append(lines, line)
+	})
+	require.Equal(t, []string{"foo", "bar"}, lines)
+
+	stderr := &strings.Builder{}
+	logDriverStderr(strings.NewReader("foo\n"), 42, (&RunOptions{Stderr: stderr}).logger())
+	require.Equal(t, "playwright driver[42]: foo\n", stderr.String())
+}
+
+func TestInstallPlaywrightRequestedBrowsersWithExistingDriver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	driverName, err := getDriverName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		t.Skip(err)
+	}
+	driverDirectory := t.TempDir()
+	logPath := filepath.Join(driverDirectory, "commands.log")
+	script := fmt.Sprintf("#!/bin/sh\nif [ \"$1\" = \"--version\" ]; then echo Version %s; exit 0; fi\necho \"$@\" >> %s\n", driverVersion, logPath)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(driverDirectory, driverName), []byte(script), 0755))
+
+	_, err = installPlaywright(&RunOptions{
+		DriverDirectory: driverDirectory,
+		Browsers:        []string{"firefox"},
+		Verbose:         Bool(false),
+	})
+	require.NoError(t, err)
+	commands, err := ioutil.ReadFile(logPath)
+	require.NoError(t, err)
+	require.Equal(t, "--install firefox\n", string(commands))
+}
+
+func TestUninstallKeepsSharedBrowsers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	driverName, err := getDriverName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		t.Skip(err)
+	}
+	driverDirectory := filepath.Join(t.TempDir(), "driver")
+	browsersPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(driverDirectory, 0777))
+	script := fmt.Sprintf("#!/bin/sh\nif [ \"$1\" = \"--version\" ]; then echo Version %s; exit 0; fi\nmkdir -p \"$%s/$2-1234\"\n", driverVersion, browsersPathEnv)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(driverDirectory, driverName), []byte(script), 0755))
+	shared := filepath.Join(browsersPath, "chromium-4321")
+	require.NoError(t, os.MkdirAll(shared, 0777))
+
+	options := &InstallOptions{
+		Browsers:        []string{"firefox"},
+		BrowsersPath:    browsersPath,
+		DriverDirectory: driverDirectory,
+		Verbose:         Bool(false),
+	}
+	require.NoError(t, Install(options))
+	require.DirExists(t, filepath.Join(browsersPath, "firefox-1234"))
+
+	require.NoError(t, Uninstall(options))
+	_, err = os.Stat(filepath.Join(browsersPath, "firefox-1234"))
+	require.True(t, os.IsNotExist(err))
+	require.DirExists(t, shared)
+	_, err = os.Stat(driverDirectory)
+	require.True(t, os.IsNotExist(err))
+}
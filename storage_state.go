@@ -0,0 +1,175 @@
+package playwright
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+)
+
+// StorageState is the cookies and the localStorage of a context, e.g. of a
+// logged in session which is reused by the StorageState option of
+// Browser.NewContext.
+type StorageState struct {
+	Cookies []NetworkCookie `json:"cookies"`
+	Origins []OriginState   `json:"origins"`
+}
+
+// OriginState is the localStorage of an origin, e.g. "https://example.com".
+type OriginState struct {
+	Origin       string             `json:"origin"`
+	LocalStorage []LocalStorageItem `json:"localStorage"`
+}
+
+type LocalStorageItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// readStorageState reads a StorageState which was written as JSON to the path.
+func readStorageState(path string) (*StorageState, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read storage state: %w", err)
+	}
+	state := &StorageState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("could not parse storage state: %w", err)
+	}
+	return state, nil
+}
+
+// setStorageState adds the cookies of the state to the context and writes the
+// localStorage of its origins from a temporary page, on which the requests to
+// the origins are fulfilled without reaching the server.
+func (b *BrowserContext) setStorageState(state *StorageState) error {
+	if len(state.Cookies) > 0 {
+		cookies := make([]SetNetworkCookieParam, len(state.Cookies))
+		for i, cookie := range state.Cookies {
+			cookies[i] = SetNetworkCookieParam{
+				Name:     cookie.Name,
+				Value:    cookie.Value,
+				Domain:   String(cookie.Domain),
+				Path:     String(cookie.Path),
+				Expires:  Int(cookie.Expires),
+				HttpOnly: Bool(cookie.HttpOnly),
+				Secure:   Bool(cookie.Secure),
+			}
+			if cookie.SameSite != "" {
+				cookies[i].SameSite = String(cookie.SameSite)
+			}
+		}
+		if err := b.AddCookies(cookies...); err != nil {
+			return fmt.Errorf("could not add cookies: %w", err)
+		}
+	}
+	if len(state.Origins) == 0 {
+		return nil
+	}
+	page, err := b.NewPage()
+	if err != nil {
+		return err
+	}
+	defer page.Close()
+	err = page.Route("**/*", func(route *Route, request *Request) {
+		if err := route.Fulfill(RouteFulfillOptions{
+			Body:        "<html></html>",
+			ContentType: String("text/html"),
+		}); err != nil {
+			log.Printf("could not fulfill storage state request: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, origin := range state.Origins {
+		if _, err := page.Goto(origin.Origin); err != nil {
+			return fmt.Errorf("could not open origin %s: %w", origin.Origin, err)
+		}
+		items := map[string]interface{}{}
+		for _, item := range origin.LocalStorage {
+			items[item.Name] = item.Value
+		}
+		_, err := page.Evaluate(`items => {
+			for (const [name, value] of Object.entries(items))
+				localStorage.setItem(name, value);
+		}`, items)
+		if err != nil {
+			return fmt.Errorf("could not set localStorage of %s: %w", origin.Origin, err)
+		}
+	}
+	return nil
+}
+
+// StorageState returns the cookies of the context and the localStorage of the
+// origins of its open pages, the path option writes it as JSON to the file,
+// which the StorageStatePath option of Browser.NewContext reads.
+func (b *BrowserContext) StorageState(path ...string) (*StorageState, error) {
+	cookies, err := b.Cookies()
+	if err != nil {
+		return nil, err
+	}
+	state := &StorageState{
+		Cookies: make([]NetworkCookie, len(cookies)),
+		Origins: make([]OriginState, 0),
+	}
+	for i, cookie := range cookies {
+		state.Cookies[i] = *cookie
+	}
+	origins := map[string]bool{}
+	for _, page := range b.Pages() {
+		if page.Isclosed() {
+			continue
+		}
+		for _, frame := range page.Frames() {
+			result, err := frame.Evaluate(`() => {
+				if (location.origin === 'null')
+					return null;
+				try {
+					return { origin: location.origin, storage: { ...localStorage } };
+				} catch (e) {
+					return null;
+				}
+			}`)
+			if err != nil {
+				return nil, fmt.Errorf("could not read localStorage: %w", err)
+			}
+			origin, ok := result.(map[string]interface{})
+			if !ok || origins[origin["origin"].(string)] {
+				continue
+			}
+			origins[origin["origin"].(string)] = true
+			storage := origin["storage"].(map[string]interface{})
+			names := make([]string, 0, len(storage))
+			for name := range storage {
+				names = append(names, name)
+			}
+			if len(names) == 0 {
+				continue
+			}
+			sort.Strings(names)
+			originState := OriginState{
+				Origin:       origin["origin"].(string),
+				LocalStorage: make([]LocalStorageItem, len(names)),
+			}
+			for i, name := range names {
+				originState.LocalStorage[i] = LocalStorageItem{
+					Name:  name,
+					Value: storage[name].(string),
+				}
+			}
+			state.Origins = append(state.Origins, originState)
+		}
+	}
+	if len(path) == 1 {
+		content, err := json.Marshal(state)
+		if err != nil {
+			return nil, fmt.Errorf("could not serialize storage state: %w", err)
+		}
+		if err := ioutil.WriteFile(path[0], content, 0o644); err != nil {
+			return nil, fmt.Errorf("could not write storage state: %w", err)
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,98 @@
+package playwright
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStorageState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{
+		"cookies": [{"name": "session", "value": "42", "domain": "localhost", "path": "/", "expires": -1}],
+		"origins": [{"origin": "http://localhost", "localStorage": [{"name": "token", "value": "secret"}]}]
+	}`), 0o644))
+	state, err := readStorageState(path)
+	require.NoError(t, err)
+	require.Equal(t, &StorageState{
+		Cookies: []NetworkCookie{{Name: "session", Value: "42", Domain: "localhost", Path: "/", Expires: -1}},
+		Origins: []OriginState{{
+			Origin:       "http://localhost",
+			LocalStorage: []LocalStorageItem{{Name: "token", Value: "secret"}},
+		}},
+	}, state)
+
+	_, err = readStorageState(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestBrowserContextStorageStateOption(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		StorageState: &StorageState{
+			Cookies: []NetworkCookie{{
+				Name:     "session",
+				Value:    "42",
+				Domain:   "localhost",
+				Path:     "/",
+				Expires:  -1,
+				SameSite: "Lax",
+			}},
+			Origins: []OriginState{{
+				Origin:       helper.server.PREFIX,
+				LocalStorage: []LocalStorageItem{{Name: "token", Value: "secret"}},
+			}},
+		},
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	helper.utils.AssertEval(t, page, "document.cookie", "session=42")
+	helper.utils.AssertEval(t, page, "localStorage.getItem('token')", "secret")
+}
+
+func TestBrowserContextStorageState(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	_, err := helper.Page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	_, err = helper.Page.Evaluate(`() => {
+		localStorage.setItem('token', 'secret');
+		document.cookie = 'session=42';
+	}`)
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "storage-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+	state, err := helper.Context.StorageState(path)
+	require.NoError(t, err)
+	require.Len(t, state.Cookies, 1)
+	require.Equal(t, "session", state.Cookies[0].Name)
+	require.Equal(t, []OriginState{{
+		Origin:       helper.server.PREFIX,
+		LocalStorage: []LocalStorageItem{{Name: "token", Value: "secret"}},
+	}}, state.Origins)
+
+	context, err := helper.Browser.NewContext(BrowserNewContextOptions{
+		StorageStatePath: String(path),
+	})
+	require.NoError(t, err)
+	defer context.Close()
+	page, err := context.NewPage()
+	require.NoError(t, err)
+	_, err = page.Goto(helper.server.EMPTY_PAGE)
+	require.NoError(t, err)
+	helper.utils.AssertEval(t, page, "document.cookie", "session=42")
+	helper.utils.AssertEval(t, page, "localStorage.getItem('token')", "secret")
+}
@@ -2,6 +2,7 @@ package playwright
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -12,41 +13,82 @@ import (
 	"gopkg.in/square/go-jose.v2/json"
 )
 
-type Transport struct {
-	stdin    io.WriteCloser
-	stdout   io.ReadCloser
-	dispatch func(msg *Message)
-	rLock    sync.Mutex
+// Transport delivers the protocol messages between the client and the driver.
+// Besides the stdin/stdout pipe of a local driver and the WebSocket of
+// BrowserType.Connect, custom transports can be passed to RunWithTransport.
+type Transport interface {
+	// Read blocks until the next message arrives. It returns io.EOF once the
+	// other side closed the transport.
+	Read() (*Message, error)
+	// Write sends a message to the other side. It may be called concurrently.
+	Write(message map[string]interface{}) error
+	// Close closes the transport, pending and later Reads return.
+	Close() error
 }
 
-func (t *Transport) Start() error {
-	reader := bufio.NewReader(t.stdout)
-	for {
-		lengthContent := make([]byte, 4)
-		_, err := io.ReadFull(reader, lengthContent)
-		if err == io.EOF {
-			return nil
-		} else if err != nil {
-			return fmt.Errorf("could not read padding: %w", err)
-		}
-		length := binary.LittleEndian.Uint32(lengthContent)
+// pipeTransport speaks the protocol over the stdin and stdout of the driver
+// process, every message is prefixed with its length.
+type pipeTransport struct {
+	stdin  io.WriteCloser
+	reader *bufio.Reader
+	wLock  sync.Mutex
+	// lengthContent is only used by Read, which is not called concurrently.
+	lengthContent [4]byte
+}
 
-		msg := &Message{}
-		if err := json.NewDecoder(io.LimitReader(reader, int64(length))).Decode(&msg); err != nil {
-			return fmt.Errorf("could not parse json: %w", err)
-		}
-		if os.Getenv("DEBUGP") != "" {
-			fmt.Print("RECV>")
-			if err := json.NewEncoder(os.Stderr).Encode(msg); err != nil {
-				log.Printf("could not create json: %v", err)
-			}
+// bufferPool holds the buffers for encoding and decoding messages, so that
+// high message rates do not allocate a new buffer for each message.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	// do not keep huge buffers, e.g. of screenshots, alive
+	if buf.Cap() > 1<<20 {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+func (t *pipeTransport) Read() (*Message, error) {
+	_, err := io.ReadFull(t.reader, t.lengthContent[:])
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read padding: %w", err)
+	}
+	length := binary.LittleEndian.Uint32(t.lengthContent[:])
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := io.CopyN(buf, t.reader, int64(length)); err != nil {
+		return nil, fmt.Errorf("could not read message: %w", err)
+	}
+	msg := &Message{
+		size: int(length),
+	}
+	if err := json.Unmarshal(buf.Bytes(), &msg); err != nil {
+		return nil, fmt.Errorf("could not parse json: %w", err)
+	}
+	if os.Getenv("DEBUGP") != "" {
+		fmt.Print("RECV>")
+		if err := json.NewEncoder(os.Stderr).Encode(msg); err != nil {
+			log.Printf("could not create json: %v", err)
 		}
-		t.dispatch(msg)
 	}
+	return msg, nil
 }
 
-func (t *Transport) Stop() error {
-	return nil
+func (t *pipeTransport) Close() error {
+	return t.stdin.Close()
 }
 
 type errorPayload struct {
@@ -64,37 +106,37 @@ type Message struct {
 	Error  *struct {
 		Error errorPayload `json:"error"`
 	} `json:"error"`
+	// size is the encoded size of the message, if known to the transport.
+	size int
 }
 
-func (t *Transport) Send(message map[string]interface{}) error {
-	msg, err := json.Marshal(message)
-	if err != nil {
+func (t *pipeTransport) Write(message map[string]interface{}) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	// reserve the length prefix, which is known after encoding
+	buf.Write([]byte{0, 0, 0, 0})
+	if err := json.NewEncoder(buf).Encode(message); err != nil {
 		return fmt.Errorf("could not marshal json: %w", err)
 	}
 	if os.Getenv("DEBUGP") != "" {
 		fmt.Print("SEND>")
-		if err := json.NewEncoder(os.Stderr).Encode(message); err != nil {
+		if _, err := os.Stderr.Write(buf.Bytes()[4:]); err != nil {
 			log.Printf("could not create json: %v", err)
 		}
 	}
-	lengthPadding := make([]byte, 4)
-	t.rLock.Lock()
-	defer t.rLock.Unlock()
-	binary.LittleEndian.PutUint32(lengthPadding, uint32(len(msg)))
-	if _, err = t.stdin.Write(lengthPadding); err != nil {
-		return err
-	}
-	if _, err = t.stdin.Write(msg); err != nil {
-		return err
-	}
-
-	return nil
+	// drop the newline which Encode appends
+	buf.Truncate(buf.Len() - 1)
+	msg := buf.Bytes()
+	binary.LittleEndian.PutUint32(msg[:4], uint32(len(msg)-4))
+	t.wLock.Lock()
+	defer t.wLock.Unlock()
+	_, err := t.stdin.Write(msg)
+	return err
 }
 
-func newTransport(stdin io.WriteCloser, stdout io.ReadCloser, dispatch func(msg *Message)) *Transport {
-	return &Transport{
-		stdout:   stdout,
-		stdin:    stdin,
-		dispatch: dispatch,
+func newPipeTransport(stdin io.WriteCloser, stdout io.ReadCloser) *pipeTransport {
+	return &pipeTransport{
+		stdin:  stdin,
+		reader: bufio.NewReader(stdout),
 	}
 }
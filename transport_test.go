@@ -0,0 +1,85 @@
+package playwright
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestPipeTransportRoundTrip(t *testing.T) {
+	reader, writer := io.Pipe()
+	transport := newPipeTransport(nopWriteCloser{writer}, reader)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			err := transport.Write(map[string]interface{}{
+				"id":     i,
+				"guid":   "Page@1",
+				"method": "click",
+			})
+			if err != nil {
+				// fails the Read of the test
+				writer.CloseWithError(err)
+				return
+			}
+		}
+		writer.Close()
+	}()
+	for i := 1; i <= 3; i++ {
+		msg, err := transport.Read()
+		require.NoError(t, err)
+		require.Equal(t, i, msg.ID)
+		require.Equal(t, "Page@1", msg.GUID)
+		require.Equal(t, "click", msg.Method)
+	}
+	_, err := transport.Read()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestPipeTransportLengthPrefix(t *testing.T) {
+	reader, writer := io.Pipe()
+	transport := newPipeTransport(nopWriteCloser{writer}, reader)
+	written := make(chan error, 1)
+	go func() {
+		written <- transport.Write(map[string]interface{}{"id": 1})
+	}()
+	lengthContent := make([]byte, 4)
+	_, err := io.ReadFull(reader, lengthContent)
+	require.NoError(t, err)
+	msg := make([]byte, binary.LittleEndian.Uint32(lengthContent))
+	_, err = io.ReadFull(reader, msg)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":1}`, string(msg))
+	require.NoError(t, <-written)
+}
+
+func BenchmarkPipeTransport(b *testing.B) {
+	reader, writer := io.Pipe()
+	transport := newPipeTransport(nopWriteCloser{writer}, reader)
+	message := map[string]interface{}{
+		"id":     1,
+		"guid":   "Page@1",
+		"method": "evaluateExpression",
+		"params": map[string]interface{}{"expression": "() => document.title"},
+	}
+	go func() {
+		for i := 0; i < b.N; i++ {
+			_ = transport.Write(message)
+		}
+	}()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := transport.Read(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package playwright
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"gopkg.in/square/go-jose.v2/json"
+)
+
+// webSocketTransport speaks the Playwright protocol over a WebSocket, every
+// message is sent as a single text frame.
+type webSocketTransport struct {
+	conn   *websocket.Conn
+	wLock  sync.Mutex
+	closed bool
+}
+
+func (t *webSocketTransport) Read() (*Message, error) {
+	_, data, err := t.conn.ReadMessage()
+	if err != nil {
+		t.wLock.Lock()
+		closed := t.closed
+		t.wLock.Unlock()
+		if closed || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("could not read message: %w", err)
+	}
+	msg := &Message{
+		size: len(data),
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("could not parse json: %w", err)
+	}
+	if os.Getenv("DEBUGP") != "" {
+		fmt.Fprintf(os.Stderr, "RECV>%s\n", data)
+	}
+	return msg, nil
+}
+
+func (t *webSocketTransport) Close() error {
+	t.wLock.Lock()
+	defer t.wLock.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	_ = t.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return t.conn.Close()
+}
+
+func (t *webSocketTransport) Write(message map[string]interface{}) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(message); err != nil {
+		return fmt.Errorf("could not marshal json: %w", err)
+	}
+	msg := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if os.Getenv("DEBUGP") != "" {
+		fmt.Fprintf(os.Stderr, "SEND>%s\n", msg)
+	}
+	t.wLock.Lock()
+	defer t.wLock.Unlock()
+	return t.conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+func newWebSocketTransport(conn *websocket.Conn) *webSocketTransport {
+	return &webSocketTransport{
+		conn: conn,
+	}
+}
@@ -1,25 +1,56 @@
 package playwright
 
+import "crypto/tls"
+
 type BrowserNewContextOptions struct {
-	AcceptDownloads   *bool                             `json:"acceptDownloads"`
-	IgnoreHTTPSErrors *bool                             `json:"ignoreHTTPSErrors"`
-	BypassCSP         *bool                             `json:"bypassCSP"`
-	Viewport          *BrowserNewContextViewport        `json:"viewport"`
-	UserAgent         *string                           `json:"userAgent"`
-	DeviceScaleFactor *int                              `json:"deviceScaleFactor"`
-	IsMobile          *bool                             `json:"isMobile"`
-	HasTouch          *bool                             `json:"hasTouch"`
-	JavaScriptEnabled *bool                             `json:"javaScriptEnabled"`
-	TimezoneId        *string                           `json:"timezoneId"`
-	Geolocation       *BrowserNewContextGeolocation     `json:"geolocation"`
-	Locale            *string                           `json:"locale"`
-	Permissions       []string                          `json:"permissions"`
-	ExtraHTTPHeaders  map[string]string                 `json:"extraHTTPHeaders"`
-	Offline           *bool                             `json:"offline"`
-	HttpCredentials   *BrowserNewContextHttpCredentials `json:"httpCredentials"`
-	ColorScheme       *string                           `json:"colorScheme"`
-	Logger            interface{}                       `json:"logger"`
-	RecordVideos      *BrowserNewContextRecordVideos    `json:"_recordVideos"`
+	AcceptDownloads *bool `json:"acceptDownloads"`
+	// IgnoreHTTPSErrors accepts invalid certificates, e.g. self-signed ones.
+	IgnoreHTTPSErrors *bool `json:"ignoreHTTPSErrors"`
+	// BypassCSP ignores the Content-Security-Policy of the pages, e.g. for
+	// AddScriptTag.
+	BypassCSP *bool                      `json:"bypassCSP"`
+	Viewport  *BrowserNewContextViewport `json:"viewport"`
+	// UserAgent overrides the user agent of the pages, in their requests and
+	// in navigator.userAgent.
+	UserAgent         *string `json:"userAgent"`
+	DeviceScaleFactor *int    `json:"deviceScaleFactor"`
+	IsMobile          *bool   `json:"isMobile"`
+	HasTouch          *bool   `json:"hasTouch"`
+	// JavaScriptEnabled set to false disables JavaScript in the pages, scripts
+	// passed to Evaluate still run.
+	JavaScriptEnabled *bool `json:"javaScriptEnabled"`
+	// TimezoneId emulates the time zone of the pages, e.g. "Europe/Berlin".
+	TimezoneId *string `json:"timezoneId"`
+	// Geolocation emulates the position of the pages, reading it needs the
+	// "geolocation" permission, e.g. of the Permissions option.
+	Geolocation *BrowserNewContextGeolocation `json:"geolocation"`
+	// Locale emulates the locale of the pages, e.g. "de-DE", in the Intl API
+	// and the Accept-Language header.
+	Locale *string `json:"locale"`
+	// Permissions are granted to all pages, e.g. "geolocation" or
+	// "notifications".
+	Permissions      []string          `json:"permissions"`
+	ExtraHTTPHeaders map[string]string `json:"extraHTTPHeaders"`
+	Offline          *bool             `json:"offline"`
+	// HttpCredentials answer the HTTP basic authentication of the pages.
+	HttpCredentials *BrowserNewContextHttpCredentials `json:"httpCredentials"`
+	// ColorScheme emulates prefers-color-scheme: "light", "dark" or
+	// "no-preference", see Page.EmulateMedia to change it later.
+	ColorScheme  *string                        `json:"colorScheme"`
+	Logger       interface{}                    `json:"logger"`
+	RecordVideos *BrowserNewContextRecordVideos `json:"_recordVideos"`
+	// RecordVideo records a video of each page, see Page.Video. The browser
+	// needs the VideosPath launch option.
+	RecordVideo *BrowserNewContextRecordVideo `json:"-"`
+	// StorageState populates the cookies and the localStorage of the context,
+	// e.g. with the one of BrowserContext.StorageState after a login.
+	StorageState *StorageState `json:"-"`
+	// StorageStatePath is a JSON file of a StorageState, the alternative to
+	// the StorageState option.
+	StorageStatePath *string `json:"-"`
+	// NoViewport disables the fixed viewport of the pages, their size follows
+	// the window of the browser. Viewport is ignored then.
+	NoViewport *bool `json:"-"`
 }
 type BrowserNewPageOptions struct {
 	AcceptDownloads   *bool                          `json:"acceptDownloads"`
@@ -43,11 +74,17 @@ type BrowserNewPageOptions struct {
 	RecordVideos      *BrowserNewPageRecordVideos    `json:"_recordVideos"`
 }
 type BrowserContextGrantPermissionsOptions struct {
+	// Origin to which the permissions are granted, e.g.
+	// "https://example.com", all origins by default.
 	Origin *string `json:"origin"`
 }
 type BrowserContextWaitForEventOptions struct {
+	// Predicate is a func of the payload of the event, e.g. func(*Page) bool,
+	// which returns true for the awaited one.
 	Predicate interface{} `json:"predicate"`
-	Timeout   *int        `json:"timeout"`
+	// Timeout in milliseconds, defaults to the one of the context, 0 waits
+	// forever.
+	Timeout *int `json:"timeout"`
 }
 type PageCloseOptions struct {
 	RunBeforeUnload *bool `json:"runBeforeUnload"`
@@ -92,7 +129,8 @@ type PageDispatchEventOptions struct {
 	Timeout   *int        `json:"timeout"`
 }
 type PageEmulateMediaOptions struct {
-	Media       interface{} `json:"media"`
+	Media interface{} `json:"media"`
+	// ColorScheme is "light", "dark", "no-preference" or Null() to reset it.
 	ColorScheme interface{} `json:"colorScheme"`
 }
 type PageFillOptions struct {
@@ -458,6 +496,12 @@ type BrowserTypeConnectOptions struct {
 	SlowMo     *int        `json:"slowMo"`
 	Logger     interface{} `json:"logger"`
 	Timeout    *int        `json:"timeout"`
+	// Headers are sent with the WebSocket handshake, e.g. for authentication.
+	Headers map[string]string `json:"-"`
+	// TLSConfig is used for wss:// endpoints.
+	TLSConfig *tls.Config `json:"-"`
+	// EnableCompression negotiates per message deflate with the server.
+	EnableCompression *bool `json:"-"`
 }
 type BrowserTypeLaunchOptions struct {
 	Headless          *bool                   `json:"headless"`
@@ -467,32 +511,48 @@ type BrowserTypeLaunchOptions struct {
 	Proxy             *BrowserTypeLaunchProxy `json:"proxy"`
 	DownloadsPath     *string                 `json:"downloadsPath"`
 	VideosPath        *string                 `json:"_videosPath"`
-	ChromiumSandbox   *bool                   `json:"chromiumSandbox"`
-	FirefoxUserPrefs  map[string]interface{}  `json:"firefoxUserPrefs"`
-	HandleSIGINT      *bool                   `json:"handleSIGINT"`
-	HandleSIGTERM     *bool                   `json:"handleSIGTERM"`
-	HandleSIGHUP      *bool                   `json:"handleSIGHUP"`
-	Logger            interface{}             `json:"logger"`
-	Timeout           *int                    `json:"timeout"`
-	Env               map[string]interface{}  `json:"env"`
-	Devtools          *bool                   `json:"devtools"`
-	SlowMo            *int                    `json:"slowMo"`
+	// ChromiumSandbox enables the sandbox of Chromium, which needs user
+	// namespaces in containers. It is disabled by default, the same as
+	// passing the --no-sandbox argument.
+	ChromiumSandbox *bool `json:"chromiumSandbox"`
+	// FirefoxUserPrefs are set in the about:config of Firefox, e.g.
+	// "network.proxy.type": 0.
+	FirefoxUserPrefs map[string]interface{} `json:"firefoxUserPrefs"`
+	HandleSIGINT     *bool                  `json:"handleSIGINT"`
+	HandleSIGTERM    *bool                  `json:"handleSIGTERM"`
+	HandleSIGHUP     *bool                  `json:"handleSIGHUP"`
+	Logger           interface{}            `json:"logger"`
+	Timeout          *int                   `json:"timeout"`
+	Env              map[string]interface{} `json:"env"`
+	// Devtools opens the DevTools for each page, only Chromium supports it.
+	// It implies Headless false unless Headless is set.
+	Devtools *bool `json:"devtools"`
+	SlowMo   *int  `json:"slowMo"`
+	// Channel of Chromium launches the installed branded browser instead of the
+	// downloaded one: "chrome", "chrome-beta", "chrome-dev", "chrome-canary",
+	// "msedge", "msedge-beta", "msedge-dev" or "msedge-canary".
+	Channel *string `json:"-"`
+	// HeadlessMode of Chromium: "new" renders like the headed browser, it needs
+	// a Chrome 109 or newer, e.g. of the Channel option. "old" is the default.
+	HeadlessMode *string `json:"-"`
 }
 type BrowserTypeLaunchPersistentContextOptions struct {
-	Headless          *bool                                              `json:"headless"`
-	ExecutablePath    *string                                            `json:"executablePath"`
-	Args              []string                                           `json:"args"`
-	IgnoreDefaultArgs interface{}                                        `json:"ignoreDefaultArgs"`
-	Proxy             *BrowserTypeLaunchPersistentContextProxy           `json:"proxy"`
-	AcceptDownloads   *bool                                              `json:"acceptDownloads"`
-	DownloadsPath     *string                                            `json:"downloadsPath"`
-	ChromiumSandbox   *bool                                              `json:"chromiumSandbox"`
-	HandleSIGINT      *bool                                              `json:"handleSIGINT"`
-	HandleSIGTERM     *bool                                              `json:"handleSIGTERM"`
-	HandleSIGHUP      *bool                                              `json:"handleSIGHUP"`
-	Logger            interface{}                                        `json:"logger"`
-	Timeout           *int                                               `json:"timeout"`
-	Env               map[string]interface{}                             `json:"env"`
+	Headless          *bool                                    `json:"headless"`
+	ExecutablePath    *string                                  `json:"executablePath"`
+	Args              []string                                 `json:"args"`
+	IgnoreDefaultArgs interface{}                              `json:"ignoreDefaultArgs"`
+	Proxy             *BrowserTypeLaunchPersistentContextProxy `json:"proxy"`
+	AcceptDownloads   *bool                                    `json:"acceptDownloads"`
+	DownloadsPath     *string                                  `json:"downloadsPath"`
+	ChromiumSandbox   *bool                                    `json:"chromiumSandbox"`
+	HandleSIGINT      *bool                                    `json:"handleSIGINT"`
+	HandleSIGTERM     *bool                                    `json:"handleSIGTERM"`
+	HandleSIGHUP      *bool                                    `json:"handleSIGHUP"`
+	Logger            interface{}                              `json:"logger"`
+	Timeout           *int                                     `json:"timeout"`
+	Env               map[string]interface{}                   `json:"env"`
+	// Devtools opens the DevTools for each page, only Chromium supports it.
+	// It implies Headless false unless Headless is set.
 	Devtools          *bool                                              `json:"devtools"`
 	SlowMo            *int                                               `json:"slowMo"`
 	IgnoreHTTPSErrors *bool                                              `json:"ignoreHTTPSErrors"`
@@ -513,6 +573,16 @@ type BrowserTypeLaunchPersistentContextOptions struct {
 	ColorScheme       *string                                            `json:"colorScheme"`
 	VideosPath        *string                                            `json:"_videosPath"`
 	RecordVideos      *BrowserTypeLaunchPersistentContextRecordVideos    `json:"_recordVideos"`
+	// Channel of Chromium launches the installed branded browser instead of the
+	// downloaded one: "chrome", "chrome-beta", "chrome-dev", "chrome-canary",
+	// "msedge", "msedge-beta", "msedge-dev" or "msedge-canary".
+	Channel *string `json:"-"`
+	// HeadlessMode of Chromium: "new" renders like the headed browser, it needs
+	// a Chrome 109 or newer, e.g. of the Channel option. "old" is the default.
+	HeadlessMode *string `json:"-"`
+	// NoViewport disables the fixed viewport of the pages, their size follows
+	// the window of the browser. Viewport is ignored then.
+	NoViewport *bool `json:"-"`
 }
 type BrowserTypeLaunchServerOptions struct {
 	Headless          *bool                         `json:"headless"`
@@ -531,7 +601,9 @@ type BrowserTypeLaunchServerOptions struct {
 	Logger            interface{}                   `json:"logger"`
 	Timeout           *int                          `json:"timeout"`
 	Env               map[string]interface{}        `json:"env"`
-	Devtools          *bool                         `json:"devtools"`
+	// Devtools opens the DevTools for each page, only Chromium supports it.
+	// It implies Headless false unless Headless is set.
+	Devtools *bool `json:"devtools"`
 }
 type ChromiumBrowserStartTracingOptions struct {
 	Page        interface{} `json:"page"`
@@ -696,6 +768,14 @@ type BrowserNewContextRecordVideos struct {
 	Width  *int `json:"width"`
 	Height *int `json:"height"`
 }
+type BrowserNewContextRecordVideo struct {
+	// Dir is the directory into which the videos are moved when the context
+	// is closed, they stay in the VideosPath of the browser otherwise.
+	Dir *string
+	// Size of the videos, defaults to the viewport scaled down to fit into
+	// 800x800.
+	Size *BrowserNewContextRecordVideos
+}
 type BrowserNewPageViewport struct {
 	Width  *int `json:"width"`
 	Height *int `json:"height"`
@@ -761,9 +841,16 @@ type ElementHandleHoverPosition struct {
 	X *int `json:"x"`
 	Y *int `json:"y"`
 }
+
+// BrowserTypeLaunchProxy routes all traffic of the browser through a proxy.
 type BrowserTypeLaunchProxy struct {
-	Server   *string `json:"server"`
-	Bypass   *string `json:"bypass"`
+	// Server of the HTTP or SOCKS proxy, e.g. "http://myproxy.com:3128" or
+	// "socks5://myproxy.com:3128"
+	Server *string `json:"server"`
+	// Bypass is a comma-separated list of domains which are not proxied,
+	// e.g. ".com, chromium.org, .domain.com"
+	Bypass *string `json:"bypass"`
+	// Username and Password authenticate at an HTTP proxy
 	Username *string `json:"username"`
 	Password *string `json:"password"`
 }
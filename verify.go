@@ -0,0 +1,104 @@
+package playwright
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var browserNames = []string{"chromium", "firefox", "webkit"}
+
+// VerifyReport describes the state of the installation, see Verify.
+type VerifyReport struct {
+	// DriverPath is the location where the driver is expected.
+	DriverPath string
+	// DriverInstalled is true if the driver exists and can be executed.
+	DriverInstalled bool
+	// DriverVersion is the version of the installed driver.
+	DriverVersion string
+	// DriverUpToDate is true if the installed driver has the version which
+	// this package targets, see DriverVersion().
+	DriverUpToDate bool
+	// BrowsersPath is the folder where the browsers are stored.
+	BrowsersPath string
+	// Browsers contains an entry for every known browser.
+	Browsers []BrowserReport
+	// WouldDownload lists what Install would download, e.g. "driver" or
+	// "chromium".
+	WouldDownload []string
+}
+
+// BrowserReport describes the installation of a single browser.
+type BrowserReport struct {
+	Name string
+	// Revisions are the installed builds of the browser, e.g. "799411".
+	Revisions []string
+}
+
+// Installed reports whether any build of the browser is installed.
+func (b BrowserReport) Installed() bool {
+	return len(b.Revisions) > 0
+}
+
+// Verify inspects the installation without changing anything, so environments
+// can be checked before running Install or Run.
+func Verify(options ...*InstallOptions) (*VerifyReport, error) {
+	option := getInstallOptions(options)
+	driverFolder, err := getDriverFolder(option)
+	if err != nil {
+		return nil, err
+	}
+	_, driverName, err := getDriverURL()
+	if err != nil {
+		return nil, err
+	}
+	report := &VerifyReport{
+		DriverPath: filepath.Join(driverFolder, driverName),
+	}
+	if _, err := os.Stat(report.DriverPath); err == nil {
+		if report.DriverVersion, err = getDriverVersion(report.DriverPath); err == nil {
+			report.DriverInstalled = true
+			report.DriverUpToDate = report.DriverVersion == driverVersion
+		}
+	}
+	if !report.DriverUpToDate {
+		report.WouldDownload = append(report.WouldDownload, "driver")
+	}
+
+	report.BrowsersPath, err = getBrowsersFolder(option)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(report.BrowsersPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not read browsers folder: %w", err)
+	}
+	wantedBrowsers := option.Browsers
+	if len(wantedBrowsers) == 0 {
+		wantedBrowsers = browserNames
+	}
+	for _, name := range browserNames {
+		browser := BrowserReport{Name: name}
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), name+"-") {
+				browser.Revisions = append(browser.Revisions, strings.TrimPrefix(entry.Name(), name+"-"))
+			}
+		}
+		report.Browsers = append(report.Browsers, browser)
+		if !browser.Installed() && containsString(wantedBrowsers, name) {
+			report.WouldDownload = append(report.WouldDownload, name)
+		}
+	}
+	return report, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package playwright
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	browsersPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(browsersPath, "chromium-799411"), 0777))
+	report, err := Verify(&InstallOptions{
+		DriverDirectory: t.TempDir(),
+		BrowsersPath:    browsersPath,
+		Browsers:        []string{"chromium", "firefox"},
+	})
+	require.NoError(t, err)
+	require.False(t, report.DriverInstalled)
+	require.False(t, report.DriverUpToDate)
+	require.Equal(t, browsersPath, report.BrowsersPath)
+	require.Equal(t, []BrowserReport{
+		{Name: "chromium", Revisions: []string{"799411"}},
+		{Name: "firefox"},
+		{Name: "webkit"},
+	}, report.Browsers)
+	require.Equal(t, []string{"driver", "firefox"}, report.WouldDownload)
+}
@@ -0,0 +1,155 @@
+package playwright
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Video is the recording of a page of a context with the RecordVideo option,
+// see Page.Video. The file is complete once the context is closed.
+type Video struct {
+	page         *Page
+	startedOnce  sync.Once
+	started      chan struct{}
+	relativePath string
+	finishOnce   sync.Once
+	finished     chan struct{}
+	finishErr    error
+}
+
+func newVideo(page *Page) *Video {
+	return &Video{
+		page:     page,
+		started:  make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+// setRelativePath is called with the path of the recording relative to the
+// VideosPath of the browser once it started.
+func (v *Video) setRelativePath(relativePath string) {
+	v.startedOnce.Do(func() {
+		v.relativePath = relativePath
+		close(v.started)
+	})
+}
+
+// driverPath returns the path at which the driver writes the recording.
+func (v *Video) driverPath() (string, error) {
+	context := v.page.browserContext
+	if context == nil || context.browser == nil || context.browser.videosPath == "" {
+		return "", errors.New("video recording needs the VideosPath launch option")
+	}
+	return filepath.Join(context.browser.videosPath, v.relativePath), nil
+}
+
+// Path returns the path of the recording, which is in the Dir of the
+// RecordVideo option if one was given. It waits for the recording to start.
+func (v *Video) Path() (string, error) {
+	select {
+	case <-v.started:
+	case <-v.page.browserContext.closed:
+		select {
+		case <-v.started:
+		default:
+			return "", errors.New("page did not record a video")
+		}
+	}
+	path, err := v.driverPath()
+	if err != nil {
+		return "", err
+	}
+	if dir := v.page.browserContext.recordVideoDir; dir != "" {
+		return filepath.Join(dir, filepath.Base(path)), nil
+	}
+	return path, nil
+}
+
+// finish moves the complete recording into the Dir of the RecordVideo
+// option, it is called when the context is closed.
+func (v *Video) finish() {
+	v.finishOnce.Do(func() {
+		defer close(v.finished)
+		select {
+		case <-v.started:
+		default:
+			v.finishErr = errors.New("page did not record a video")
+			return
+		}
+		dir := v.page.browserContext.recordVideoDir
+		if dir == "" {
+			return
+		}
+		from, err := v.driverPath()
+		if err != nil {
+			v.finishErr = err
+			return
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			v.finishErr = fmt.Errorf("could not create video dir: %w", err)
+			return
+		}
+		if err := moveFile(from, filepath.Join(dir, filepath.Base(from))); err != nil {
+			v.finishErr = fmt.Errorf("could not move video: %w", err)
+		}
+	})
+}
+
+// SaveAs copies the recording to the path, it waits for the context to be
+// closed.
+func (v *Video) SaveAs(path string) error {
+	<-v.finished
+	if v.finishErr != nil {
+		return v.finishErr
+	}
+	from, err := v.Path()
+	if err != nil {
+		return err
+	}
+	return copyFile(from, path)
+}
+
+// Delete deletes the recording, it waits for the context to be closed.
+func (v *Video) Delete() error {
+	<-v.finished
+	if v.finishErr != nil {
+		return v.finishErr
+	}
+	path, err := v.Path()
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// moveFile renames the file, it falls back to a copy across file systems.
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	if err := copyFile(from, to); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+func copyFile(from, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	destination, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		return err
+	}
+	return destination.Close()
+}
@@ -0,0 +1,154 @@
+package playwright
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoRecordVideoDir(t *testing.T) {
+	videosPath, err := ioutil.TempDir("", "videos")
+	require.NoError(t, err)
+	defer os.RemoveAll(videosPath)
+	dir := filepath.Join(videosPath, "moved")
+
+	connection, fake := newFakeConnection()
+	connection.Dispatch(&Message{
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Browser",
+			"guid":        "Browser",
+			"initializer": map[string]interface{}{},
+		},
+	})
+	browser := fromChannel(connection.objects["Browser"].channel).(*Browser)
+	browser.videosPath = videosPath
+	created := make(chan map[string]interface{}, 1)
+	go func() {
+		message := <-fake.sent
+		created <- message
+		connection.Dispatch(&Message{
+			GUID:   "Browser",
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type":        "BrowserContext",
+				"guid":        "BrowserContext",
+				"initializer": map[string]interface{}{},
+			},
+		})
+		connection.Dispatch(&Message{
+			ID: message["id"].(int),
+			Result: map[string]interface{}{
+				"context": map[string]interface{}{"guid": "BrowserContext"},
+			},
+		})
+	}()
+	_, err = browser.NewContext(BrowserNewContextOptions{
+		RecordVideo: &BrowserNewContextRecordVideo{
+			Dir:  String(dir),
+			Size: &BrowserNewContextRecordVideos{Width: Int(320), Height: Int(240)},
+		},
+	})
+	require.NoError(t, err)
+	message := <-created
+	require.Equal(t, "newContext", message["method"])
+	params, err := json.Marshal(message["params"])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"_recordVideos": {"width": 320, "height": 240}}`, string(params))
+
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type":        "Frame",
+			"guid":        "Frame",
+			"initializer": map[string]interface{}{"name": "", "url": "about:blank"},
+		},
+	})
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "__create__",
+		Params: map[string]interface{}{
+			"type": "Page",
+			"guid": "Page",
+			"initializer": map[string]interface{}{
+				"mainFrame": map[string]interface{}{"guid": "Frame"},
+			},
+		},
+	})
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "page",
+		Params: map[string]interface{}{"page": map[string]interface{}{"guid": "Page"}},
+	})
+	page := fromChannel(connection.objects["Page"].channel).(*Page)
+	video := page.Video()
+	require.NotNil(t, video)
+
+	connection.Dispatch(&Message{
+		GUID:   "Page",
+		Method: "videoStarted",
+		Params: map[string]interface{}{"relativePath": "page.webm"},
+	})
+	path, err := video.Path()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "page.webm"), path)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(videosPath, "page.webm"), []byte("webm"), 0o644))
+
+	connection.Dispatch(&Message{GUID: "BrowserContext", Method: "close"})
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "webm", string(content))
+	copied := filepath.Join(videosPath, "copy.webm")
+	require.NoError(t, video.SaveAs(copied))
+	require.FileExists(t, copied)
+	require.NoError(t, video.Delete())
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestVideoWithoutRecordVideo(t *testing.T) {
+	helper := BeforeEach(t)
+	defer helper.AfterEach()
+	require.Nil(t, helper.Page.Video())
+}
+
+func TestVideoNotStarted(t *testing.T) {
+	connection, _ := newFakeConnection()
+	create := func(parent, objectType, guid string, initializer map[string]interface{}) {
+		connection.Dispatch(&Message{
+			GUID:   parent,
+			Method: "__create__",
+			Params: map[string]interface{}{
+				"type":        objectType,
+				"guid":        guid,
+				"initializer": initializer,
+			},
+		})
+	}
+	create("", "BrowserContext", "BrowserContext", map[string]interface{}{})
+	create("BrowserContext", "Frame", "Frame", map[string]interface{}{"name": "", "url": "about:blank"})
+	create("BrowserContext", "Page", "Page", map[string]interface{}{
+		"mainFrame": map[string]interface{}{"guid": "Frame"},
+	})
+	context := fromChannel(connection.objects["BrowserContext"].channel).(*BrowserContext)
+	context.recordVideo = true
+	connection.Dispatch(&Message{
+		GUID:   "BrowserContext",
+		Method: "page",
+		Params: map[string]interface{}{"page": map[string]interface{}{"guid": "Page"}},
+	})
+	page := fromChannel(connection.objects["Page"].channel).(*Page)
+	video := page.Video()
+	require.NotNil(t, video)
+
+	connection.Dispatch(&Message{GUID: "BrowserContext", Method: "close"})
+	require.EqualError(t, video.SaveAs("page.webm"), "page did not record a video")
+	require.EqualError(t, video.Delete(), "page did not record a video")
+	_, err := video.Path()
+	require.EqualError(t, err, "page did not record a video")
+}